@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+// Command line flags for the clone command
+var (
+	cloneNameFlag string
+	cloneSetFlags []string
+)
+
+// Clone command
+var cloneCmd = &cobra.Command{
+	Use:   "clone <name-or-id>",
+	Short: "Copy an existing command under a new name",
+	Long: `Copy an existing command, apply field overrides and save it as a new command.
+
+Overrides use the form field names, e.g.
+  go-recipe clone "Disk Space" --name "Disk Space (home)" --set Command="df -h ~" --set Tags=disk,home`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		newName := strings.TrimSpace(cloneNameFlag)
+		if newName == "" {
+			return fmt.Errorf("--name is required")
+		}
+
+		commands, err := loadCommands()
+		if err != nil {
+			return err
+		}
+
+		idx, err := findCommand(commands, args[0])
+		if err != nil {
+			return err
+		}
+		if nameTaken(commands, newName) {
+			return fmt.Errorf("a command named %q already exists", newName)
+		}
+
+		// Reuse the form setters so overrides are parsed exactly like TUI input
+		m := model.Model{FormCommand: commands[idx]}
+		m.FormCommand.Tags = append([]string{}, commands[idx].Tags...)
+		m.FormCommand.Aliases = nil // aliases must stay unique; set new ones with --set Aliases=...
+		for _, set := range cloneSetFlags {
+			name, value, ok := strings.Cut(set, "=")
+			if !ok {
+				return fmt.Errorf("invalid --set %q, expected field=value", set)
+			}
+			field, ok := model.ParseFormField(name)
+			if !ok {
+				return fmt.Errorf("unknown field %q in --set", name)
+			}
+			m.SetFormFieldValue(field, value)
+		}
+
+		clone := m.FormCommand
+		clone.ID = config.NewCommandID(commands)
+		clone.Name = newName
+		clone.LastRun = time.Time{}
+		clone.LastExit = nil
+		if strings.TrimSpace(clone.Command) == "" {
+			return fmt.Errorf("command must not be empty")
+		}
+		for _, alias := range clone.Aliases {
+			if aliasTaken(commands, alias) {
+				return fmt.Errorf("alias %q is already used by another command", alias)
+			}
+		}
+
+		if err := config.SaveConfig(append(commands, clone)); err != nil {
+			return err
+		}
+		fmt.Printf("Cloned %q as %q (ID %s)\n", commands[idx].Name, clone.Name, clone.ID)
+		return nil
+	},
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+// Completion command
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|fish|powershell>",
+	Short: "Generate a shell completion script",
+	Long: `Generate a completion script for your shell. Command names are completed
+for run, clone and export-script, e.g. go-recipe run <TAB>.
+
+Bash (needs the bash-completion package):
+  source <(go-recipe completion bash)
+Zsh:
+  go-recipe completion zsh > "${fpath[1]}/_go-recipe"
+Fish:
+  go-recipe completion fish > ~/.config/fish/completions/go-recipe.fish
+PowerShell:
+  go-recipe completion powershell | Out-String | Invoke-Expression`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(out, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(out)
+		case "fish":
+			return rootCmd.GenFishCompletion(out, true)
+		case "powershell":
+			return rootCmd.GenPowerShellCompletionWithDesc(out)
+		}
+		return fmt.Errorf("unsupported shell %q", args[0])
+	},
+}
+
+// completeCommandNames completes the first argument with the names and
+// aliases of the saved commands, described by their command line. The file is
+// only read: unlike LoadConfig, completing never writes defaults or backs up a
+// broken file.
+func completeCommandNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Completion skips the persistent hooks, so apply --config here
+	config.SetConfigPath(configPathFlag)
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	commands, err := config.DecodeCommands(configPath, data)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var names []string
+	for _, c := range commands {
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			if strings.HasPrefix(model.Fold(name), model.Fold(toComplete)) {
+				names = append(names, name+"\t"+c.Command)
+			}
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+// modulePath is the canonical Go module path of go-recipe
+const modulePath = "github.com/Tomlord1122/go-recipe"
+
+// Command line flags for the doctor command
+var doctorCheckModuleFlag bool
+
+// doctorCheck is a single named diagnostic
+type doctorCheck struct {
+	name string
+	run  func() error
+}
+
+// Doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration (and optionally the build) for problems",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		checks := []doctorCheck{
+			{"commands file", checkCommandsFile},
+			{"settings file", checkSettingsFile},
+		}
+		if doctorCheckModuleFlag {
+			checks = append([]doctorCheck{{"module path", checkModulePath}}, checks...)
+		}
+
+		failed := 0
+		for _, c := range checks {
+			if err := c.run(); err != nil {
+				failed++
+				fmt.Printf("✗ %s: %v\n", c.name, err)
+			} else {
+				fmt.Printf("✓ %s\n", c.name)
+			}
+		}
+
+		// Warnings point out likely mistakes but don't fail the run
+		if commands, err := readCommandsFile(); err == nil {
+			for _, c := range commands {
+				for _, warning := range config.LintCommand(c) {
+					fmt.Printf("⚠ %s: %s\n", c.Name, warning)
+				}
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+// checkModulePath verifies the binary was built from the canonical module
+// and doesn't link packages from a stale module path
+func checkModulePath() error {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return fmt.Errorf("build information is not available in this binary")
+	}
+	if info.Main.Path != modulePath {
+		return fmt.Errorf("built from module %q, expected %q", info.Main.Path, modulePath)
+	}
+	if !strings.HasPrefix(info.Path, modulePath+"/") {
+		return fmt.Errorf("main package %q is outside module %q", info.Path, modulePath)
+	}
+	for _, dep := range info.Deps {
+		if strings.HasPrefix(dep.Path, "github.com/Tomlord1122/") {
+			return fmt.Errorf("links packages from a second module path: %s", dep.Path)
+		}
+	}
+	return nil
+}
+
+// checkCommandsFile verifies the commands file parses. A missing file is fine,
+// defaults are written on first launch.
+func checkCommandsFile() error {
+	_, err := readCommandsFile()
+	return err
+}
+
+// readCommandsFile parses the commands file without creating it; a missing
+// file yields no commands
+func readCommandsFile() ([]model.Command, error) {
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	commands, err := config.DecodeCommands(configPath, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not valid: %w", configPath, err)
+	}
+	return commands, nil
+}
+
+// checkSettingsFile verifies the optional settings file parses
+func checkSettingsFile() error {
+	if _, err := config.LoadSettings(); err != nil {
+		return err
+	}
+	return nil
+}
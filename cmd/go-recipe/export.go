@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/update"
+	"github.com/spf13/cobra"
+)
+
+// Command line flags for the export-script command
+var exportScriptOutputFlag string
+
+// Export command
+var exportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Write all commands to a JSON or YAML file (- for stdout)",
+	Long: `Write all commands to a file that can be shared and read back with
+go-recipe import. Files ending in .yaml or .yml get YAML, anything else JSON.
+Use - to write JSON to stdout.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commands, err := loadCommands()
+		if err != nil {
+			return err
+		}
+		data, err := config.EncodeCommands(args[0], commands)
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+
+		if args[0] == "-" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(args[0], data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", args[0], err)
+		}
+		fmt.Printf("Exported %d command(s) to %s\n", len(commands), args[0])
+		return nil
+	},
+}
+
+// Export-script command
+var exportScriptCmd = &cobra.Command{
+	Use:   "export-script <name-or-id>",
+	Short: "Print a saved command as a standalone bash script",
+	Long: `Turn a saved command into a bash script that runs it without go-recipe:
+it changes to the command's working directory, exports its environment
+(including the env file), runs its pre- and post-commands and feeds its stdin
+file, e.g. to hand a recipe to someone else:
+  go-recipe export-script "Deploy" -o deploy.sh
+
+UseShell commands are embedded as written; others are quoted word by word, as
+go-recipe runs them without a shell. Placeholders are left for you to fill in.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commands, err := loadCommands()
+		if err != nil {
+			return err
+		}
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return fmt.Errorf("failed to load settings: %w", err)
+		}
+		update.SetProjectRoot(settings.ProjectRoot)
+
+		idx, err := findCommand(commands, args[0])
+		if err != nil {
+			return err
+		}
+		script, err := update.ShellScript(commands[idx])
+		if err != nil {
+			return err
+		}
+
+		if exportScriptOutputFlag == "" || exportScriptOutputFlag == "-" {
+			fmt.Print(script)
+			return nil
+		}
+		if err := os.WriteFile(exportScriptOutputFlag, []byte(script), 0755); err != nil {
+			return fmt.Errorf("failed to write %s: %w", exportScriptOutputFlag, err)
+		}
+		fmt.Printf("Exported %q to %s\n", commands[idx].Name, exportScriptOutputFlag)
+		return nil
+	},
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+// Command line flags for the fmt command
+var fmtCheckFlag bool
+
+// Fmt command
+var fmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "Validate and rewrite the commands file in canonical form",
+	Long: `Validate and rewrite the commands file in canonical form.
+
+Commands are sorted by category and name, tags are normalized and the file is
+re-indented the same way go-recipe saves it. Fails if the file can't be parsed.
+With --check nothing is written and a non-zero exit means the file isn't formatted.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, err := config.GetConfigPath()
+		if err != nil {
+			return err
+		}
+
+		// Read the file directly: LoadConfig would write defaults for a missing file
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		commands, err := config.DecodeCommands(configPath, data)
+		if err != nil {
+			return fmt.Errorf("failed to parse config file: %w", err)
+		}
+
+		var changes []string
+
+		retagged := 0
+		for i, c := range commands {
+			tags := model.NormalizeTags(c.Tags, false)
+			if !slices.Equal(tags, c.Tags) {
+				commands[i].Tags = tags
+				retagged++
+			}
+		}
+		if retagged > 0 {
+			changes = append(changes, fmt.Sprintf("normalized tags on %d command(s)", retagged))
+		}
+
+		sorted := slices.Clone(commands)
+		config.SortCommands(sorted)
+		moved := 0
+		for i := range sorted {
+			if sorted[i].ID != commands[i].ID || sorted[i].Name != commands[i].Name {
+				moved++
+			}
+		}
+		if moved > 0 {
+			changes = append(changes, fmt.Sprintf("reordered %d command(s)", moved))
+		}
+
+		formatted, err := config.EncodeCommands(configPath, sorted)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(formatted, data) {
+			fmt.Printf("%s is already formatted (%d commands)\n", configPath, len(sorted))
+			return nil
+		}
+		if len(changes) == 0 {
+			changes = append(changes, "reindented")
+		}
+
+		if fmtCheckFlag {
+			for _, change := range changes {
+				fmt.Printf("would have %s\n", change)
+			}
+			return fmt.Errorf("%s is not formatted", configPath)
+		}
+
+		if err := config.SaveConfig(sorted); err != nil {
+			return err
+		}
+		for _, change := range changes {
+			fmt.Printf("%s\n", change)
+		}
+		fmt.Printf("Formatted %s (%d commands)\n", configPath, len(sorted))
+		return nil
+	},
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"text/tabwriter"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+// Command line flags for the grep command
+var (
+	grepIgnoreCaseFlag bool
+	grepJSONFlag       bool
+)
+
+// Grep command
+var grepCmd = &cobra.Command{
+	Use:   "grep <pattern>",
+	Short: "List commands whose command text matches a regular expression",
+	Long: `List commands whose command text matches a regular expression.
+
+Unlike the TUI filter, only the Command field is searched, which makes it easy
+to find every command using a tool, e.g. go-recipe grep '^(docker|kubectl)\b'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pattern := args[0]
+		if grepIgnoreCaseFlag {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+
+		commands, err := loadCommands()
+		if err != nil {
+			return err
+		}
+
+		matches := []model.Command{}
+		for _, c := range commands {
+			if re.MatchString(c.Command) {
+				matches = append(matches, c)
+			}
+		}
+
+		if grepJSONFlag {
+			data, err := json.MarshalIndent(matches, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode matches: %w", err)
+			}
+			fmt.Println(string(data))
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			for _, c := range matches {
+				fmt.Fprintf(w, "%s\t%s\n", c.Name, c.Command)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+
+		if len(matches) == 0 {
+			return fmt.Errorf("no command matches %q", args[0])
+		}
+		return nil
+	},
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+// Command line flags for the history command
+var (
+	historyCommandFlag string
+	historyLimitFlag   int
+	historyJSONFlag    bool
+)
+
+// History command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Print the most recent command runs",
+	Long: `Print the most recent runs recorded in ~/.go-recipe/history.jsonl, newest
+last: when each command started, its exit code and how long it took. Runs from
+the TUI (foreground, background and interactive) and from go-recipe run are
+recorded; watch mode repetitions are not.
+
+--command limits the list to one command, by ID, name or alias.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := config.LoadHistory()
+		if err != nil {
+			return err
+		}
+
+		if historyCommandFlag != "" {
+			id := historyCommandFlag
+			// Accept names and aliases too while the command still exists
+			if commands, err := loadCommands(); err == nil {
+				if idx, err := findCommand(commands, historyCommandFlag); err == nil {
+					id = commands[idx].ID
+				}
+			}
+			var matching []model.HistoryEntry
+			for _, entry := range entries {
+				if entry.CommandID == id {
+					matching = append(matching, entry)
+				}
+			}
+			entries = matching
+		}
+		if historyLimitFlag > 0 && len(entries) > historyLimitFlag {
+			entries = entries[len(entries)-historyLimitFlag:]
+		}
+
+		if historyJSONFlag {
+			if entries == nil {
+				entries = []model.HistoryEntry{}
+			}
+			data, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode history: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+		if len(entries) == 0 {
+			fmt.Println("No runs recorded yet.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "STARTED\tNAME\tEXIT\tDURATION\tID")
+		for _, entry := range entries {
+			duration := time.Duration(entry.DurationMs) * time.Millisecond
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", entry.StartTime.Local().Format("2006-01-02 15:04:05"),
+				entry.Name, entry.ExitCode, duration, entry.CommandID)
+		}
+		return w.Flush()
+	},
+}
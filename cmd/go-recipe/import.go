@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"github.com/Tomlord1122/go-recipe/pkg/update"
+	"github.com/spf13/cobra"
+)
+
+// Command line flags for the import command
+var importReplaceFlag bool
+
+// Import command
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Merge commands from a JSON or YAML file (- for stdin)",
+	Long: `Merge commands from a file written by go-recipe export (YAML for .yaml/.yml).
+
+Commands are matched by ID: new IDs are added, identical ones are skipped and
+changed ones are updated (keeping the local run history), with a summary of
+the changed fields. --replace discards the current commands instead. Tags and
+aliases are normalized as the form does, and nothing is saved if the result
+would repeat an ID or an alias.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		imported, err := readImportFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		commands, err := loadCommands()
+		if err != nil {
+			return err
+		}
+
+		if importReplaceFlag {
+			assignMissingIDs(imported, nil)
+			if err := update.ValidateCommands(imported); err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+			if err := config.SaveConfig(imported); err != nil {
+				return err
+			}
+			fmt.Printf("Replaced %d command(s) with %d imported command(s)\n", len(commands), len(imported))
+			return nil
+		}
+
+		assignMissingIDs(imported, commands)
+		added, updated, unchanged := 0, 0, 0
+		for _, in := range imported {
+			idx := -1
+			for i, c := range commands {
+				if c.ID == in.ID {
+					idx = i
+					break
+				}
+			}
+			if idx < 0 {
+				commands = append(commands, in)
+				added++
+				continue
+			}
+
+			changes := changedFields(commands[idx], in)
+			if len(changes) == 0 {
+				unchanged++
+				continue
+			}
+			fmt.Printf("~ %s (%s): %s\n", in.Name, in.ID, strings.Join(changes, "; "))
+			commands[idx] = in
+			updated++
+		}
+
+		if added+updated > 0 {
+			if err := update.ValidateCommands(commands); err != nil {
+				return fmt.Errorf("importing %s would leave the commands invalid: %w", args[0], err)
+			}
+			if err := config.SaveConfig(commands); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Imported %d command(s): %d added, %d updated, %d unchanged\n",
+			len(imported), added, updated, unchanged)
+		return nil
+	},
+}
+
+// readImportFile parses a JSON command list from path, or stdin for -
+func readImportFile(path string) ([]model.Command, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	commands, err := config.DecodeCommands(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for i, c := range commands {
+		if strings.TrimSpace(c.Name) == "" || strings.TrimSpace(c.Command) == "" {
+			return nil, fmt.Errorf("command #%d in %s has no name or command", i+1, path)
+		}
+		// Tags and aliases as the form would save them
+		commands[i].Tags = model.NormalizeTags(c.Tags, false)
+		commands[i].Aliases = model.NormalizeTags(c.Aliases, false)
+	}
+	return commands, nil
+}
+
+// assignMissingIDs gives imported commands without an ID a fresh one that
+// collides with neither existing nor other imported commands
+func assignMissingIDs(imported, existing []model.Command) {
+	for i := range imported {
+		if imported[i].ID == "" {
+			imported[i].ID = config.NewCommandID(append(slices.Clone(existing), imported...))
+		}
+	}
+}
+
+// changedFields describes the editable fields that differ between two
+// versions of a command, using the form field names
+func changedFields(local, incoming model.Command) []string {
+	before := model.Model{FormCommand: local}
+	after := model.Model{FormCommand: incoming}
+	var changes []string
+	for f := model.FormField(0); f < model.FieldCount; f++ {
+		if a, b := before.GetFormFieldValue(f), after.GetFormFieldValue(f); a != b {
+			changes = append(changes, fmt.Sprintf("%s %q → %q", f, a, b))
+		}
+	}
+	if local.Protected != incoming.Protected {
+		changes = append(changes, fmt.Sprintf("Protected %t → %t", local.Protected, incoming.Protected))
+	}
+	return changes
+}
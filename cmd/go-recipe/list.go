@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"github.com/Tomlord1122/go-recipe/pkg/update"
+	"github.com/spf13/cobra"
+)
+
+// Command line flags for the list command
+var (
+	listFormatFlag   string
+	listJSONFlag     bool
+	listCategoryFlag string
+)
+
+// List command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print the configured commands",
+	Long: `Print the configured commands without launching the TUI.
+
+By default a table of name, category, command and tags is printed. --json
+prints the commands as JSON instead, and --format takes a Go text/template
+that is executed once per command,
+e.g. go-recipe list --format '{{.Name}}\t{{.Command}}' | fzf`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if listJSONFlag && listFormatFlag != "" {
+			return fmt.Errorf("--json and --format can't be combined")
+		}
+		var tmpl *template.Template
+		if listFormatFlag != "" {
+			var err error
+			if tmpl, err = parseListFormat(listFormatFlag); err != nil {
+				return err
+			}
+		}
+
+		commands, err := loadCommands()
+		if err != nil {
+			return err
+		}
+
+		listed := []model.Command{}
+		for _, c := range commands {
+			if update.InCategory(c, listCategoryFlag) {
+				listed = append(listed, c)
+			}
+		}
+
+		switch {
+		case listJSONFlag:
+			data, err := json.MarshalIndent(listed, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode commands: %w", err)
+			}
+			fmt.Println(string(data))
+		case tmpl != nil:
+			for _, c := range listed {
+				if err := tmpl.Execute(os.Stdout, c); err != nil {
+					return fmt.Errorf("failed to render command %q: %w", c.Name, err)
+				}
+				fmt.Fprintln(os.Stdout)
+			}
+		default:
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tCATEGORY\tCOMMAND\tTAGS")
+			for _, c := range listed {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Name, c.Category, c.Command, strings.Join(c.Tags, ","))
+			}
+			return w.Flush()
+		}
+		return nil
+	},
+}
+
+// parseListFormat compiles the --format template, turning the literal
+// escape sequences \t and \n into real tabs and newlines so shell-quoted
+// formats behave as expected. A join function is provided for Tags.
+func parseListFormat(format string) (*template.Template, error) {
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	tmpl, err := template.New("list").Funcs(template.FuncMap{"join": strings.Join}).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return tmpl, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -20,7 +21,11 @@ var (
 )
 
 // Command line flags
-var runInBackgroundFlag bool
+var (
+	runInBackgroundFlag bool
+	configPathFlag      string
+	versionCheckFlag    bool
+)
 
 // Application is the main Bubble Tea application
 type Application struct {
@@ -41,21 +46,72 @@ func (a Application) View() string {
 	return view.Render(a.model)
 }
 
+// loadConfig loads the commands. A commands file that couldn't be parsed has
+// been backed up and replaced by the defaults by then; that is returned as a
+// warning, so callers carry on with the defaults and tell the user.
+func loadConfig() (commands []model.Command, warning string, err error) {
+	commands, err = config.LoadConfig()
+	var recovered *config.RecoveredError
+	if errors.As(err, &recovered) {
+		return commands, recovered.Error(), nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+	return commands, "", nil
+}
+
+// loadCommands loads the commands for a subcommand, reporting a recovered
+// commands file on stderr
+func loadCommands() ([]model.Command, error) {
+	commands, warning, err := loadConfig()
+	if warning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	return commands, err
+}
+
 // initializeModel loads configuration and sets up the initial model
 func initializeModel() (model.Model, error) {
 	// Create basic model
 	m := model.NewModel(runInBackgroundFlag)
 
 	// Load commands from config
-	commands, err := config.LoadConfig()
+	// A corrupt file is backed up and replaced by the defaults; say so in the
+	// UI until the user dismisses it
+	commands, warning, err := loadConfig()
 	if err != nil {
-		return m, fmt.Errorf("Failed to load config: %v", err)
+		return m, err
 	}
+	m.Warning = warning
+
+	// Load user settings
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return m, fmt.Errorf("Failed to load settings: %v", err)
+	}
+	m.Settings = settings
+	update.SetShellFlags(settings.ShellFlags)
+	update.SetWindowsShell(settings.WindowsShell)
+	update.SetProjectRoot(settings.ProjectRoot)
+
+	// Load the color theme
+	theme, explicit, err := config.LoadTheme()
+	if err != nil {
+		return m, fmt.Errorf("Failed to load theme: %v", err)
+	}
+	view.UseTheme(theme, explicit)
 
 	// Set commands and categories
 	m.AllCommands = commands
 	m.VisibleCommands = commands
-	m.Categories = config.GetCategories(commands)
+	m.Categories = config.GetCategories(commands, settings.CategoryOrder)
+
+	// Restore the category, filter and sort order of the last session; a
+	// broken state file only costs that, so it doesn't stop the app
+	if state, err := config.LoadState(); err == nil {
+		m = update.RestoreSessionState(m, state)
+	}
 
 	return m, nil
 }
@@ -65,6 +121,12 @@ var rootCmd = &cobra.Command{
 	Use:   "go-recipe",
 	Short: "A TUI application for executing commands",
 	Long:  `A Terminal User Interface (TUI) application built with Cobra, Bubble Tea, and Lip Gloss.`,
+	// Errors are printed once by main; usage is only shown via --help
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		config.SetConfigPath(configPathFlag)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Initialize the model
 		initialModel, err := initializeModel()
@@ -79,11 +141,17 @@ var rootCmd = &cobra.Command{
 		}
 
 		// Run the program
-		p := tea.NewProgram(app, tea.WithAltScreen())
-		if _, err := p.Run(); err != nil {
+		p := tea.NewProgram(app, tea.WithAltScreen(), tea.WithMouseCellMotion())
+		finalModel, err := p.Run()
+		if err != nil {
 			fmt.Printf("Error running program: %v\n", err)
 			os.Exit(1)
 		}
+		if app, ok := finalModel.(Application); ok {
+			if err := config.SaveState(update.SessionState(app.model)); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+		}
 	},
 }
 
@@ -95,6 +163,9 @@ var versionCmd = &cobra.Command{
 		fmt.Printf("go-recipe version %s\n", version)
 		fmt.Printf("commit: %s\n", commit)
 		fmt.Printf("build date: %s\n", date)
+		if versionCheckFlag {
+			printUpdateCheck()
+		}
 	},
 }
 
@@ -103,10 +174,72 @@ func main() {
 	// The message will be displayed in the help command
 	rootCmd.PersistentFlags().BoolVarP(&runInBackgroundFlag, "background", "b", false,
 		"Run selected commands in the background")
+	rootCmd.PersistentFlags().StringVar(&configPathFlag, "config", "",
+		"Commands file to use instead of ~/.go-recipe/commands.json")
 
 	// Add version command
+	versionCmd.Flags().BoolVar(&versionCheckFlag, "check", false, "Check GitHub for a newer release")
 	rootCmd.AddCommand(versionCmd)
 
+	// Add list command
+	listCmd.Flags().StringVar(&listFormatFlag, "format", "",
+		"Go text/template applied to each command (e.g. '{{.Name}}\\t{{.Command}}')")
+	listCmd.Flags().BoolVar(&listJSONFlag, "json", false, "Print the commands as JSON")
+	listCmd.Flags().StringVar(&listCategoryFlag, "category", "", "Only list commands in this category")
+	rootCmd.AddCommand(listCmd)
+
+	// Add run command
+	runCmd.Flags().BoolVar(&runDryRunFlag, "dry-run", false, "Print what would run instead of running it")
+	runCmd.Flags().StringArrayVar(&runParamFlags, "param", nil, "Value of a {{placeholder}}, e.g. --param host=example.com (repeatable)")
+	runCmd.ValidArgsFunction = completeCommandNames
+	rootCmd.AddCommand(runCmd)
+
+	// Add clone command
+	cloneCmd.Flags().StringVar(&cloneNameFlag, "name", "", "Name of the new command (required)")
+	cloneCmd.Flags().StringArrayVar(&cloneSetFlags, "set", nil, "Override a field, e.g. --set Command=\"df -h\" (repeatable)")
+	cloneCmd.ValidArgsFunction = completeCommandNames
+	rootCmd.AddCommand(cloneCmd)
+
+	// Add export and import commands
+	rootCmd.AddCommand(exportCmd)
+	importCmd.Flags().BoolVar(&importReplaceFlag, "replace", false, "Replace all commands instead of merging")
+	rootCmd.AddCommand(importCmd)
+
+	// Add export-script command
+	exportScriptCmd.Flags().StringVarP(&exportScriptOutputFlag, "output", "o", "", "Write the script to this file (made executable) instead of stdout")
+	exportScriptCmd.ValidArgsFunction = completeCommandNames
+	rootCmd.AddCommand(exportScriptCmd)
+
+	// Add history command
+	historyCmd.Flags().StringVar(&historyCommandFlag, "command", "", "Only show runs of this command (ID, name or alias)")
+	historyCmd.Flags().IntVarP(&historyLimitFlag, "limit", "n", 20, "Show at most this many of the latest runs (0 for all)")
+	historyCmd.Flags().BoolVar(&historyJSONFlag, "json", false, "Print the runs as JSON")
+	historyCmd.RegisterFlagCompletionFunc("command", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeCommandNames(cmd, nil, toComplete)
+	})
+	rootCmd.AddCommand(historyCmd)
+
+	// Add tags command
+	tagsCmd.AddCommand(tagsRenameCmd)
+	rootCmd.AddCommand(tagsCmd)
+
+	// Add doctor command
+	doctorCmd.Flags().BoolVar(&doctorCheckModuleFlag, "check-module", false,
+		"Also verify the binary was built from the "+modulePath+" module")
+	rootCmd.AddCommand(doctorCmd)
+
+	// Add fmt command
+	fmtCmd.Flags().BoolVar(&fmtCheckFlag, "check", false, "Report whether the file is formatted without rewriting it")
+	rootCmd.AddCommand(fmtCmd)
+
+	// Add grep command
+	grepCmd.Flags().BoolVarP(&grepIgnoreCaseFlag, "ignore-case", "i", false, "Match case-insensitively")
+	grepCmd.Flags().BoolVar(&grepJSONFlag, "json", false, "Print the matching commands as JSON")
+	rootCmd.AddCommand(grepCmd)
+
+	// Add completion command
+	rootCmd.AddCommand(completionCmd)
+
 	// Execute the command
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// findCommand returns the index of the command matching query. Names are
+// matched ignoring case and accents first, then aliases; if neither matches, the
+// query is treated as an ID. Ambiguous names produce an error listing the
+// candidates.
+func findCommand(commands []model.Command, query string) (int, error) {
+	var matches []int
+	for i, c := range commands {
+		if model.FoldEqual(c.Name, query) {
+			matches = append(matches, i)
+		}
+	}
+	// Aliases are unique when saved from the TUI, but a hand-edited file
+	// may still repeat one; that is reported like an ambiguous name
+	if len(matches) == 0 {
+		for i, c := range commands {
+			if slices.ContainsFunc(c.Aliases, func(alias string) bool { return model.FoldEqual(alias, query) }) {
+				matches = append(matches, i)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		for i, c := range commands {
+			if c.ID == query {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("no command named, aliased or with ID %q", query)
+	default:
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%q matches multiple commands, use an ID instead:", query)
+		for _, i := range matches {
+			fmt.Fprintf(&sb, "\n  %s  %s (%s)", commands[i].ID, commands[i].Name, commands[i].Command)
+		}
+		return -1, fmt.Errorf("%s", sb.String())
+	}
+}
+
+// aliasTaken reports whether a command already uses the given alias
+func aliasTaken(commands []model.Command, alias string) bool {
+	for _, c := range commands {
+		if slices.ContainsFunc(c.Aliases, func(a string) bool { return model.FoldEqual(a, alias) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameTaken reports whether a command with the given name already exists
+func nameTaken(commands []model.Command, name string) bool {
+	for _, c := range commands {
+		if model.FoldEqual(c.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"github.com/Tomlord1122/go-recipe/pkg/update"
+	"github.com/spf13/cobra"
+)
+
+// Command line flags for the run command
+var (
+	runDryRunFlag bool
+	runParamFlags []string
+)
+
+// Run command
+var runCmd = &cobra.Command{
+	Use:   "run <name-or-id>",
+	Short: "Run a saved command without the TUI and exit with its exit code",
+	Long: `Run a saved command without the TUI, print its formatted output and exit
+with the command's exit code, e.g. in a Makefile:
+  go-recipe run "Disk Space"
+
+Names and aliases match case-insensitively, falling back to the command ID.
+Interactive commands run attached to the terminal. {{placeholder}}s in the
+command line are filled in from --param, e.g.
+  go-recipe run ping --param host=example.com
+
+With --dry-run nothing is run: the resolved command line (placeholders filled
+in), shell, working directory and environment are printed instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commands, err := loadCommands()
+		if err != nil {
+			return err
+		}
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return fmt.Errorf("failed to load settings: %w", err)
+		}
+		update.SetShellFlags(settings.ShellFlags)
+		update.SetWindowsShell(settings.WindowsShell)
+		update.SetProjectRoot(settings.ProjectRoot)
+
+		idx, err := findCommand(commands, args[0])
+		if err != nil {
+			return err
+		}
+		command := commands[idx]
+
+		params := map[string]string{}
+		for _, param := range runParamFlags {
+			name, value, ok := strings.Cut(param, "=")
+			if !ok {
+				return fmt.Errorf("invalid --param %q, expected name=value", param)
+			}
+			params[strings.TrimSpace(name)] = value
+		}
+		command, err = update.FillPlaceholders(command, params)
+		if err != nil {
+			return fmt.Errorf("%w (pass --param name=value)", err)
+		}
+
+		if runDryRunFlag {
+			description, err := update.DescribeRun(command)
+			if err != nil {
+				return err
+			}
+			fmt.Println(update.RedactOutput(description, command, settings))
+			return nil
+		}
+
+		var result update.Result
+		if command.Interactive {
+			result = update.ExecuteCommandInteractiveAttached(command)
+			if result.Error != nil && result.ExitCode == -1 {
+				fmt.Fprintln(os.Stderr, result.Error)
+			}
+		} else {
+			result = update.ExecuteCommand(command)
+			result.Output = update.RedactOutput(result.Output, command, settings)
+			fmt.Println(update.FormatOutput(result))
+		}
+
+		if err := update.RecordHistory(result); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to record the run in the history: %v\n", err)
+		}
+		_, err = config.RecordRun(command.ID, func(stats *model.RunStats) {
+			stats.LastRun = result.StartTime
+			stats.RunCount++
+			stats.LastExit = &result.ExitCode
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to record last run: %v\n", err)
+		}
+
+		// Negative codes mean go-recipe couldn't run or had to kill the command
+		exitCode := result.ExitCode
+		if exitCode < 0 {
+			exitCode = 1
+		}
+		os.Exit(exitCode)
+		return nil
+	},
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// Tags command
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List all tags with the number of commands using them",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commands, err := loadCommands()
+		if err != nil {
+			return err
+		}
+
+		counts := config.TagCounts(commands)
+		tags := make([]string, 0, len(counts))
+		for tag := range counts {
+			tags = append(tags, tag)
+		}
+		// Most used first, then alphabetically
+		sort.Slice(tags, func(i, j int) bool {
+			if counts[tags[i]] != counts[tags[j]] {
+				return counts[tags[i]] > counts[tags[j]]
+			}
+			return tags[i] < tags[j]
+		})
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, tag := range tags {
+			fmt.Fprintf(w, "%s\t%d\n", tag, counts[tag])
+		}
+		return w.Flush()
+	},
+}
+
+// Tags rename command
+var tagsRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tag on every command that uses it",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		newTag := strings.TrimSpace(args[1])
+		if newTag == "" || strings.Contains(newTag, ",") {
+			return fmt.Errorf("invalid tag %q", args[1])
+		}
+
+		commands, err := loadCommands()
+		if err != nil {
+			return err
+		}
+
+		changed := config.RenameTag(commands, args[0], newTag)
+		if changed == 0 {
+			return fmt.Errorf("no command is tagged %q", args[0])
+		}
+		if err := config.SaveConfig(commands); err != nil {
+			return err
+		}
+		fmt.Printf("Renamed tag %q to %q on %d command(s)\n", args[0], newTag, changed)
+		return nil
+	},
+}
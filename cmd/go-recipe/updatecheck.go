@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// latestReleaseURL is the GitHub API endpoint for the newest release
+const latestReleaseURL = "https://api.github.com/repos/Tomlord1122/go-recipe/releases/latest"
+
+// updateCheckTimeout keeps `version --check` snappy when offline
+const updateCheckTimeout = 3 * time.Second
+
+// latestRelease returns the tag of the newest published release
+func latestRelease() (string, error) {
+	client := &http.Client{Timeout: updateCheckTimeout}
+	req, err := http.NewRequest(http.MethodGet, latestReleaseURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub returned %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", err
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("no release tag in response")
+	}
+	return release.TagName, nil
+}
+
+// newerVersion reports whether semver tag latest is newer than current.
+// Both may have a leading "v"; pre-release and build suffixes are ignored.
+func newerVersion(latest, current string) (bool, error) {
+	l, err := parseVersion(latest)
+	if err != nil {
+		return false, err
+	}
+	c, err := parseVersion(current)
+	if err != nil {
+		return false, err
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i], nil
+		}
+	}
+	return false, nil
+}
+
+// parseVersion splits "v1.2.3" into its major, minor and patch numbers
+func parseVersion(v string) ([3]int, error) {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) != 3 {
+		return parts, fmt.Errorf("%q is not a semantic version", v)
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return parts, fmt.Errorf("%q is not a semantic version", v)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
+
+// printUpdateCheck compares the running version with the latest release.
+// Network problems are reported but don't fail the command.
+func printUpdateCheck() {
+	latest, err := latestRelease()
+	if err != nil {
+		fmt.Printf("could not check for updates: %v\n", err)
+		return
+	}
+	newer, err := newerVersion(latest, version)
+	switch {
+	case err != nil:
+		// e.g. a "dev" build
+		fmt.Printf("latest release: %s (can't compare with version %s)\n", latest, version)
+	case newer:
+		fmt.Printf("update available: %s → %s\n", version, latest)
+		fmt.Println("  go install github.com/Tomlord1122/go-recipe/cmd/go-recipe@latest")
+	default:
+		fmt.Printf("go-recipe is up to date (latest release: %s)\n", latest)
+	}
+}
@@ -2,13 +2,20 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -16,8 +23,37 @@ const (
 	configFile = "commands.json"
 )
 
+// configPathOverride replaces the default commands file when set
+var configPathOverride string
+
+// SetConfigPath makes all loads and saves use path instead of
+// ~/.go-recipe/commands.json. An empty path restores the default.
+func SetConfigPath(path string) {
+	configPathOverride = path
+}
+
 // GetConfigPath returns the full path to the config file
 func GetConfigPath() (string, error) {
+	if configPathOverride != "" {
+		path := configPathOverride
+		if path == "~" || strings.HasPrefix(path, "~/") {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("failed to get home directory: %w", err)
+			}
+			path = filepath.Join(homeDir, path[1:])
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", fmt.Errorf("failed to create config directory: %w", err)
+		}
+		return path, nil
+	}
+	return configDirFile(configFile)
+}
+
+// configDirFile returns the path of name inside the config directory,
+// creating the directory if needed
+func configDirFile(name string) (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
@@ -28,7 +64,7 @@ func GetConfigPath() (string, error) {
 		return "", fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	return filepath.Join(configDirPath, configFile), nil
+	return filepath.Join(configDirPath, name), nil
 }
 
 // LoadConfig loads commands from the config file
@@ -53,14 +89,66 @@ func LoadConfig() ([]model.Command, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var commands []model.Command
-	if err := json.Unmarshal(data, &commands); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	commands, err := DecodeCommands(configPath, data)
+	if err != nil {
+		return recoverConfig(configPath, err)
 	}
+	applyRunStats(commands)
 
 	return commands, nil
 }
 
+// RecoveredError reports that the config file could not be parsed and was
+// moved aside. LoadConfig returns it together with the default commands, so
+// callers can carry on and show it as a warning.
+type RecoveredError struct {
+	BackupPath string // Where the unreadable file was moved
+	Err        error  // The parse error
+}
+
+func (e *RecoveredError) Error() string {
+	return fmt.Sprintf("config file could not be parsed (%v); it was moved to %s and the default commands were loaded", e.Err, e.BackupPath)
+}
+
+func (e *RecoveredError) Unwrap() error {
+	return e.Err
+}
+
+// recoverConfig moves an unparsable config file to a timestamped backup next
+// to it and starts over with the default commands. If the file can't be moved
+// the parse error is returned as is, so it is never overwritten.
+func recoverConfig(configPath string, parseErr error) ([]model.Command, error) {
+	backupPath := backupName(configPath)
+	if err := os.Rename(configPath, backupPath); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", parseErr)
+	}
+
+	defaultCommands := getDefaultCommands()
+	if err := SaveConfig(defaultCommands); err != nil {
+		return nil, err
+	}
+	return defaultCommands, &RecoveredError{BackupPath: backupPath, Err: parseErr}
+}
+
+// BackupConfig copies the config file to a timestamped backup next to it and
+// returns the backup's path, so a file about to be overwritten is kept
+func BackupConfig(configPath string) (string, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	backupPath := backupName(configPath)
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up config file: %w", err)
+	}
+	return backupPath, nil
+}
+
+// backupName returns the timestamped path a config file is backed up to
+func backupName(configPath string) string {
+	return fmt.Sprintf("%s.bak.%s", configPath, time.Now().Format("20060102-150405"))
+}
+
 // SaveConfig saves commands to the config file
 func SaveConfig(commands []model.Command) error {
 	configPath, err := GetConfigPath()
@@ -68,41 +156,231 @@ func SaveConfig(commands []model.Command) error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(commands, "", "  ")
+	data, err := EncodeCommands(configPath, commands)
 	if err != nil {
-		return fmt.Errorf("failed to marshal commands: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	if err := writeFileWithRetry(configPath, data); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
-// GetCategories extracts unique categories from commands
-func GetCategories(commands []model.Command) []string {
+// saveAttempts bounds how often a config write is tried before giving up
+const saveAttempts = 4
+
+var (
+	// saveBackoff is the delay before the first retry; it doubles each time
+	saveBackoff = 100 * time.Millisecond
+	// writeFile performs config writes; replaced to simulate failing disks
+	writeFile = os.WriteFile
+)
+
+// writeFileWithRetry writes data to path, retrying transient failures (such
+// as a flaky network home directory) with exponential backoff. Permission
+// and missing-directory errors are returned immediately.
+func writeFileWithRetry(path string, data []byte) error {
+	delay := saveBackoff
+	var err error
+	for attempt := 1; attempt <= saveAttempts; attempt++ {
+		if err = writeFile(path, data, 0644); err == nil {
+			return nil
+		}
+		if errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if attempt < saveAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return fmt.Errorf("%w (after %d attempts)", err, saveAttempts)
+}
+
+// isYAML reports whether path names a YAML commands file
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// EncodeCommands encodes commands the way SaveConfig writes them to path:
+// YAML for .yaml/.yml files, indented JSON otherwise. Run stats are left
+// out; they are kept in the state file.
+func EncodeCommands(path string, commands []model.Command) ([]byte, error) {
+	commands = slices.Clone(commands)
+	for i := range commands {
+		commands[i].LastRun = time.Time{}
+		commands[i].RunCount = 0
+		commands[i].LastExit = nil
+	}
+
+	var data []byte
+	var err error
+	if isYAML(path) {
+		data, err = yaml.Marshal(commands)
+	} else {
+		data, err = json.MarshalIndent(commands, "", "  ")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal commands: %w", err)
+	}
+	return data, nil
+}
+
+// DecodeCommands parses commands read from path, choosing YAML or JSON by
+// the file extension
+func DecodeCommands(path string, data []byte) ([]model.Command, error) {
+	var commands []model.Command
+	var err error
+	if isYAML(path) {
+		err = yaml.Unmarshal(data, &commands)
+	} else {
+		err = json.Unmarshal(data, &commands)
+	}
+	return commands, err
+}
+
+// SortCommands orders commands by category and then by name (both
+// case-insensitive), keeping the existing order for ties
+func SortCommands(commands []model.Command) {
+	sort.SliceStable(commands, func(i, j int) bool {
+		ci, cj := strings.ToLower(commands[i].Category), strings.ToLower(commands[j].Category)
+		if ci != cj {
+			return ci < cj
+		}
+		return strings.ToLower(commands[i].Name) < strings.ToLower(commands[j].Name)
+	})
+}
+
+// GetCategories extracts unique categories from commands. "All" always comes
+// first, then "Favorites" when a command is marked favorite, followed by the
+// categories named in order (if present) and then the remaining categories
+// sorted alphabetically, so the result is stable.
+func GetCategories(commands []model.Command, order []string) []string {
 	// Use a map to track unique categories
 	categoryMap := map[string]bool{}
+	favorites := false
 	for _, cmd := range commands {
 		if cmd.Category != "" {
 			categoryMap[cmd.Category] = true
 		}
+		favorites = favorites || cmd.Favorite
 	}
 
-	// Convert map keys to slice
 	categories := []string{"All"} // Always include "All" category
+	if favorites {
+		categories = append(categories, model.FavoritesCategory)
+		delete(categoryMap, model.FavoritesCategory)
+	}
+
+	// Configured order takes precedence
+	for _, category := range order {
+		if categoryMap[category] {
+			categories = append(categories, category)
+			delete(categoryMap, category)
+		}
+	}
+
+	// Sort the rest so map iteration order doesn't leak into the UI
+	rest := make([]string, 0, len(categoryMap))
 	for category := range categoryMap {
-		categories = append(categories, category)
+		rest = append(rest, category)
+	}
+	sort.Strings(rest)
+
+	return append(categories, rest...)
+}
+
+// GetTags returns the unique tags used by the commands, sorted alphabetically
+func GetTags(commands []model.Command) []string {
+	tagMap := map[string]bool{}
+	for _, cmd := range commands {
+		for _, tag := range cmd.Tags {
+			if tag != "" {
+				tagMap[tag] = true
+			}
+		}
 	}
 
-	return categories
+	tags := make([]string, 0, len(tagMap))
+	for tag := range tagMap {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// TagCounts returns how many commands use each tag
+func TagCounts(commands []model.Command) map[string]int {
+	counts := map[string]int{}
+	for _, cmd := range commands {
+		for _, tag := range cmd.Tags {
+			counts[tag]++
+		}
+	}
+	return counts
+}
+
+// RenameTag replaces the tag oldTag (case-insensitive) with newTag on all
+// commands and returns the number of commands changed
+func RenameTag(commands []model.Command, oldTag, newTag string) int {
+	changed := 0
+	for i, cmd := range commands {
+		renamed := false
+		tags := make([]string, len(cmd.Tags))
+		for j, tag := range cmd.Tags {
+			if strings.EqualFold(tag, oldTag) {
+				tag = newTag
+				renamed = true
+			}
+			tags[j] = tag
+		}
+		if renamed {
+			commands[i].Tags = model.NormalizeTags(tags, false)
+			changed++
+		}
+	}
+	return changed
+}
+
+// RenameCategory moves all commands in oldCategory to newCategory, merging
+// them into it when it already exists, and returns the number of commands
+// changed. An empty newCategory leaves the commands without a category.
+func RenameCategory(commands []model.Command, oldCategory, newCategory string) int {
+	changed := 0
+	for i, cmd := range commands {
+		if cmd.Category == oldCategory && oldCategory != newCategory {
+			commands[i].Category = newCategory
+			changed++
+		}
+	}
+	return changed
+}
+
+// NewCommandID returns an ID for a new command that none of commands uses.
+// IDs are based on the current Unix time, counting up past taken ones so two
+// commands added within the same second still get distinct IDs.
+func NewCommandID(commands []model.Command) string {
+	used := map[string]bool{}
+	for _, cmd := range commands {
+		used[cmd.ID] = true
+	}
+	next := time.Now().Unix()
+	for used[strconv.FormatInt(next, 10)] {
+		next++
+	}
+	return strconv.FormatInt(next, 10)
 }
 
 // getDefaultCommands returns a set of default commands for first-time users
 func getDefaultCommands() []model.Command {
-	if runtime.GOOS == "darwin" {
+	switch runtime.GOOS {
+	case "darwin":
 		return getDarwinCommands()
+	case "windows":
+		return getWindowsCommands()
 	}
 
 	// Default to Linux commands
@@ -246,3 +524,72 @@ func getLinuxCommands() []model.Command {
 		},
 	}
 }
+
+// getWindowsCommands returns Windows-specific commands
+func getWindowsCommands() []model.Command {
+	return []model.Command{
+		{
+			ID:          "1",
+			Name:        "Disk Space",
+			Command:     "powershell -NoProfile -Command Get-PSDrive -PSProvider FileSystem",
+			Category:    "System",
+			Description: "Shows used and free space per drive",
+			Tags:        []string{"system", "disk"},
+			LastRun:     time.Time{},
+		},
+		{
+			ID:          "2",
+			Name:        "Memory Usage",
+			Command:     "powershell -NoProfile -Command Get-CimInstance Win32_OperatingSystem | Select-Object TotalVisibleMemorySize,FreePhysicalMemory",
+			Category:    "System",
+			Description: "Shows total and free physical memory (KB)",
+			Tags:        []string{"system", "memory"},
+			LastRun:     time.Time{},
+		},
+		{
+			ID:          "3",
+			Name:        "Network Interfaces",
+			Command:     "ipconfig /all",
+			Category:    "Network",
+			Description: "Lists network interfaces",
+			Tags:        []string{"network"},
+			LastRun:     time.Time{},
+		},
+		{
+			ID:          "4",
+			Name:        "System Info",
+			Command:     "systeminfo",
+			Category:    "System",
+			Description: "Shows system information",
+			Tags:        []string{"system"},
+			LastRun:     time.Time{},
+		},
+		{
+			ID:          "5",
+			Name:        "Running Processes",
+			Command:     "tasklist",
+			Category:    "System",
+			Description: "Lists running processes",
+			Tags:        []string{"system", "process"},
+			LastRun:     time.Time{},
+		},
+		{
+			ID:          "6",
+			Name:        "Network Stats",
+			Command:     "netstat -an",
+			Category:    "Network",
+			Description: "Shows network connections and listening ports",
+			Tags:        []string{"network"},
+			LastRun:     time.Time{},
+		},
+		{
+			ID:          "7",
+			Name:        "CPU Info",
+			Command:     "powershell -NoProfile -Command Get-CimInstance Win32_Processor | Select-Object Name,NumberOfCores,MaxClockSpeed",
+			Category:    "System",
+			Description: "Shows CPU model, cores and clock speed",
+			Tags:        []string{"system", "cpu"},
+			LastRun:     time.Time{},
+		},
+	}
+}
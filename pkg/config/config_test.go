@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+func TestGetCategories(t *testing.T) {
+	commands := []model.Command{
+		{Name: "a", Category: "Network"},
+		{Name: "b", Category: "Docker"},
+		{Name: "c", Category: "System"},
+		{Name: "d", Category: "Docker"},
+		{Name: "e"},
+	}
+
+	tests := []struct {
+		name  string
+		order []string
+		want  []string
+	}{
+		{"alphabetical", nil, []string{"All", "Docker", "Network", "System"}},
+		{"configured order first", []string{"System", "Network"}, []string{"All", "System", "Network", "Docker"}},
+		{"unknown categories in order are skipped", []string{"Missing", "Network"}, []string{"All", "Network", "Docker", "System"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map iteration order changes between runs, so ask repeatedly
+			for range 20 {
+				if got := GetCategories(commands, tt.order); !slices.Equal(got, tt.want) {
+					t.Fatalf("GetCategories() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetCategoriesFavorites(t *testing.T) {
+	commands := []model.Command{
+		{Name: "a", Category: "Network", Favorite: true},
+		{Name: "b", Category: "Docker"},
+	}
+	want := []string{"All", model.FavoritesCategory, "Docker", "Network"}
+	if got := GetCategories(commands, nil); !slices.Equal(got, want) {
+		t.Errorf("GetCategories() = %v, want %v", got, want)
+	}
+}
+
+// stubWriteFile makes config writes fail failures times with err before
+// succeeding, and returns the number of attempts made so far
+func stubWriteFile(t *testing.T, failures int, err error) *int {
+	t.Helper()
+	savedWrite, savedBackoff := writeFile, saveBackoff
+	t.Cleanup(func() { writeFile, saveBackoff = savedWrite, savedBackoff })
+	saveBackoff = time.Millisecond
+
+	attempts := 0
+	writeFile = func(name string, data []byte, perm os.FileMode) error {
+		attempts++
+		if attempts <= failures {
+			return err
+		}
+		return os.WriteFile(name, data, perm)
+	}
+	return &attempts
+}
+
+func TestWriteFileWithRetry(t *testing.T) {
+	transient := &fs.PathError{Op: "write", Path: "commands.json", Err: syscall.EIO}
+	tests := []struct {
+		name         string
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"first try", 0, nil, 1, false},
+		{"transient failures then success", 2, transient, 3, false},
+		{"success on the last attempt", saveAttempts - 1, transient, saveAttempts, false},
+		{"permanent failure", 100, transient, saveAttempts, true},
+		{"permission denied is not retried", 100, fs.ErrPermission, 1, true},
+		{"missing directory is not retried", 100, fs.ErrNotExist, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := stubWriteFile(t, tt.failures, tt.err)
+			path := filepath.Join(t.TempDir(), "commands.json")
+
+			err := writeFileWithRetry(path, []byte("[]"))
+			if *attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", *attempts, tt.wantAttempts)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want it to wrap %v", err, tt.err)
+			}
+			if _, statErr := os.Stat(path); (statErr == nil) == tt.wantErr {
+				t.Errorf("file written = %v, want %v", statErr == nil, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSaveConfigReportsPermanentFailure(t *testing.T) {
+	stubWriteFile(t, 100, &fs.PathError{Op: "write", Path: "commands.json", Err: syscall.EIO})
+	SetConfigPath(filepath.Join(t.TempDir(), "commands.json"))
+	t.Cleanup(func() { SetConfigPath("") })
+
+	if err := SaveConfig([]model.Command{{ID: "1", Name: "a", Command: "ls"}}); err == nil {
+		t.Fatal("SaveConfig() succeeded, want an error")
+	}
+}
+
+func TestLoadConfigRecoversFromBrokenFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	path := filepath.Join(dir, "commands.json")
+	SetConfigPath(path)
+	t.Cleanup(func() { SetConfigPath("") })
+	broken := []byte(`[{"id": "1", "name": "half written`)
+	if err := os.WriteFile(path, broken, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	commands, err := LoadConfig()
+	var recovered *RecoveredError
+	if !errors.As(err, &recovered) {
+		t.Fatalf("LoadConfig() error = %v, want a *RecoveredError", err)
+	}
+	if !slices.EqualFunc(commands, getDefaultCommands(), func(a, b model.Command) bool { return a.Name == b.Name }) {
+		t.Errorf("LoadConfig() = %d commands, want the defaults", len(commands))
+	}
+
+	// The broken file is kept as commands.json.bak.<timestamp>
+	backups, _ := filepath.Glob(path + ".bak.*")
+	if len(backups) != 1 || backups[0] != recovered.BackupPath {
+		t.Fatalf("backups = %v, want only %s", backups, recovered.BackupPath)
+	}
+	if data, _ := os.ReadFile(recovered.BackupPath); !slices.Equal(data, broken) {
+		t.Errorf("backup holds %q, want the broken file", data)
+	}
+
+	// The defaults replace it, so the next load succeeds
+	reloaded, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("second LoadConfig() error = %v", err)
+	}
+	if len(reloaded) != len(commands) {
+		t.Errorf("second LoadConfig() = %d commands, want %d", len(reloaded), len(commands))
+	}
+}
+
+func TestRunStatsLiveInTheStateFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "commands.json")
+	SetConfigPath(path)
+	t.Cleanup(func() { SetConfigPath("") })
+	// Written before run stats moved to state.json
+	legacy := []byte(`[{"ID": "1", "Name": "a", "Command": "ls", "LastRun": "2024-05-01T10:00:00Z", "RunCount": 3}]`)
+	if err := os.WriteFile(path, legacy, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	commands, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commands[0].RunCount != 3 || commands[0].LastRun.IsZero() {
+		t.Fatalf("loaded stats = %d runs at %v, want those of the old file", commands[0].RunCount, commands[0].LastRun)
+	}
+	if err := SaveConfig(commands); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "RunCount") || strings.Contains(string(data), "LastRun") {
+		t.Errorf("commands file still holds run stats:\n%s", data)
+	}
+
+	// A run, e.g. from go-recipe run, only touches the state file
+	stats, err := RecordRun("1", func(stats *model.RunStats) { stats.RunCount++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.RunCount != 4 {
+		t.Errorf("RecordRun() = %d runs, want 4", stats.RunCount)
+	}
+	// Saving the list view state on quit keeps it
+	if err := SaveState(model.SessionState{FilterText: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	commands, err = LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commands[0].RunCount != 4 || commands[0].LastRun.IsZero() {
+		t.Errorf("reloaded stats = %d runs at %v, want 4 runs at the old time", commands[0].RunCount, commands[0].LastRun)
+	}
+}
+
+func TestNewCommandIDWithinOneSecond(t *testing.T) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	tests := []struct {
+		name     string
+		existing []model.Command
+	}{
+		{"no commands", nil},
+		{"current second taken", []model.Command{{ID: now}}},
+		{"several seconds taken", []model.Command{{ID: now}, {ID: next(now, 1)}, {ID: next(now, 2)}}},
+		{"unrelated IDs", []model.Command{{ID: "1"}, {ID: "custom"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands := slices.Clone(tt.existing)
+			seen := map[string]bool{}
+			for _, cmd := range commands {
+				seen[cmd.ID] = true
+			}
+			// Adding commands back to back keeps finding free IDs
+			for range 10 {
+				id := NewCommandID(commands)
+				if seen[id] {
+					t.Fatalf("NewCommandID() = %s, which is already used", id)
+				}
+				seen[id] = true
+				commands = append(commands, model.Command{ID: id})
+			}
+		})
+	}
+}
+
+// next returns the Unix time ID n seconds after id
+func next(id string, n int64) string {
+	v, _ := strconv.ParseInt(id, 10, 64)
+	return strconv.FormatInt(v+n, 10)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+const historyFile = "history.jsonl"
+
+// GetHistoryPath returns the path of the execution history file
+func GetHistoryPath() (string, error) {
+	return configDirFile(historyFile)
+}
+
+// AppendHistory adds an entry to the execution history. Entries are appended
+// one JSON object per line, so a run never rewrites earlier ones and a crash
+// can at worst cut off the last line.
+func AppendHistory(entry model.HistoryEntry) error {
+	path, err := GetHistoryPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return nil
+}
+
+// LoadHistory returns the recorded runs, oldest first. A missing file means
+// no history, and lines that don't parse (e.g. cut off by a crash) are skipped.
+func LoadHistory() ([]model.HistoryEntry, error) {
+	path, err := GetHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []model.HistoryEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry model.HistoryEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return entries, nil
+}
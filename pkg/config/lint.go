@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// LintCommand returns warnings about likely mistakes in a command that
+// don't prevent saving it
+func LintCommand(cmd model.Command) []string {
+	var warnings []string
+	if refs := relativePathRefs(cmd.Command); len(refs) > 0 && runsInCurrentDir(cmd) {
+		warnings = append(warnings, fmt.Sprintf(
+			"uses relative path(s) %s but runs in whatever directory go-recipe was started from; set WorkingDirMode to home, absolute or relative to pin it",
+			strings.Join(refs, ", ")))
+	}
+	return warnings
+}
+
+// runsInCurrentDir reports whether the command inherits go-recipe's working directory
+func runsInCurrentDir(cmd model.Command) bool {
+	mode := strings.ToLower(strings.TrimSpace(cmd.WorkingDirMode))
+	return mode == "" || mode == "current"
+}
+
+// relativePathRefs finds words in a command line that look like relative
+// paths: anything starting with ./ or ../, and the target of a cd
+func relativePathRefs(command string) []string {
+	words := strings.FieldsFunc(command, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ';' || r == '|' || r == '&' || r == '(' || r == ')'
+	})
+
+	var refs []string
+	seen := map[string]bool{}
+	add := func(word string) {
+		if !seen[word] {
+			seen[word] = true
+			refs = append(refs, word)
+		}
+	}
+	for i, word := range words {
+		word = strings.Trim(word, `"'`)
+		if strings.HasPrefix(word, "./") || strings.HasPrefix(word, "../") {
+			add(word)
+			continue
+		}
+		if word == "cd" && i+1 < len(words) {
+			target := strings.Trim(words[i+1], `"'`)
+			if target != "" && target != "-" && !strings.HasPrefix(target, "/") &&
+				!strings.HasPrefix(target, "~") && !strings.HasPrefix(target, "$") {
+				add(target)
+			}
+		}
+	}
+	return refs
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+const resultsDir = "results"
+
+// MaxResultOutput caps the output kept in a stored result; longer output
+// keeps only its end, where errors usually are
+const MaxResultOutput = 256 * 1024
+
+// resultPath returns the file storing the last result of a command
+func resultPath(commandID string) (string, error) {
+	dir, err := configDirFile(resultsDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create results directory: %w", err)
+	}
+	// IDs are timestamps, but keep odd IDs from escaping the directory
+	return filepath.Join(dir, filepath.Base(commandID)+".json"), nil
+}
+
+// SaveResult stores the last result of a command, truncating long output
+func SaveResult(record model.ExecutionRecord) error {
+	if len(record.Output) > MaxResultOutput {
+		record.Output = record.Output[len(record.Output)-MaxResultOutput:]
+		record.Truncated = true
+	}
+
+	path, err := resultPath(record.CommandID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	if err := writeFileWithRetry(path, data); err != nil {
+		return fmt.Errorf("failed to write result file: %w", err)
+	}
+	return nil
+}
+
+// LoadResult returns the stored last result of a command; ok is false when
+// the command has none
+func LoadResult(commandID string) (record model.ExecutionRecord, ok bool, err error) {
+	path, err := resultPath(commandID)
+	if err != nil {
+		return record, false, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return record, false, nil
+	}
+	if err != nil {
+		return record, false, fmt.Errorf("failed to read result file: %w", err)
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, false, fmt.Errorf("failed to parse result file: %w", err)
+	}
+	return record, true, nil
+}
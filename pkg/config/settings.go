@@ -0,0 +1,62 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+const settingsFile = "settings.json"
+
+// GetSettingsPath returns the full path to the settings file
+func GetSettingsPath() (string, error) {
+	return configDirFile(settingsFile)
+}
+
+// LoadSettings loads user settings, falling back to defaults for missing values
+func LoadSettings() (model.Settings, error) {
+	settings := model.DefaultSettings()
+
+	settingsPath, err := GetSettingsPath()
+	if err != nil {
+		return settings, err
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return model.DefaultSettings(), fmt.Errorf("failed to parse settings file: %w", err)
+	}
+	if settings.PinnedSlots == nil {
+		settings.PinnedSlots = map[int]string{}
+	}
+
+	return settings, nil
+}
+
+// SaveSettings saves user settings to the settings file
+func SaveSettings(settings model.Settings) error {
+	settingsPath, err := GetSettingsPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	if err := writeFileWithRetry(settingsPath, data); err != nil {
+		return fmt.Errorf("failed to write settings file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+const stateFile = "state.json"
+
+// GetStatePath returns the full path to the session state file
+func GetStatePath() (string, error) {
+	return configDirFile(stateFile)
+}
+
+// LoadState loads the list view state saved by the previous session; without
+// a state file the zero state is returned
+func LoadState() (model.SessionState, error) {
+	var state model.SessionState
+
+	statePath, err := GetStatePath()
+	if err != nil {
+		return state, err
+	}
+
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return model.SessionState{}, fmt.Errorf("failed to parse state file: %w", err)
+	}
+
+	return state, nil
+}
+
+// SaveState saves the list view state for the next session. The run stats
+// on disk are kept, since runs record them as they happen (see RecordRun),
+// possibly from another go-recipe process.
+func SaveState(state model.SessionState) error {
+	current, err := LoadState()
+	if err != nil {
+		return err
+	}
+	state.Runs = current.Runs
+	return writeState(state)
+}
+
+// RecordRun updates the run stats of the command with the given ID in the
+// state file and returns them, including runs recorded by other processes
+func RecordRun(id string, record func(*model.RunStats)) (model.RunStats, error) {
+	state, err := LoadState()
+	if err != nil {
+		return model.RunStats{}, err
+	}
+	if state.Runs == nil {
+		state.Runs = map[string]model.RunStats{}
+	}
+	stats := state.Runs[id]
+	record(&stats)
+	state.Runs[id] = stats
+	return stats, writeState(state)
+}
+
+// applyRunStats fills in the commands' run stats from the state file. Stats
+// still stored in an older commands file are moved to the state file first,
+// as saving the commands drops them. A broken state file only costs the stats.
+func applyRunStats(commands []model.Command) {
+	state, err := LoadState()
+	if err != nil {
+		return
+	}
+	migrated := false
+	for i, cmd := range commands {
+		stats, ok := state.Runs[cmd.ID]
+		if !ok {
+			if cmd.LastRun.IsZero() && cmd.RunCount == 0 && cmd.LastExit == nil {
+				continue
+			}
+			if state.Runs == nil {
+				state.Runs = map[string]model.RunStats{}
+			}
+			state.Runs[cmd.ID] = model.RunStats{LastRun: cmd.LastRun, RunCount: cmd.RunCount, LastExit: cmd.LastExit}
+			migrated = true
+			continue
+		}
+		commands[i].LastRun = stats.LastRun
+		commands[i].RunCount = stats.RunCount
+		commands[i].LastExit = stats.LastExit
+	}
+	if migrated {
+		_ = writeState(state)
+	}
+}
+
+// writeState writes state to the state file as is
+func writeState(state model.SessionState) error {
+	statePath, err := GetStatePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	if err := writeFileWithRetry(statePath, data); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+const themeFile = "theme.json"
+
+// GetThemePath returns the full path to the theme file
+func GetThemePath() (string, error) {
+	return configDirFile(themeFile)
+}
+
+// LoadTheme loads the color theme. The file picks a built-in theme by name
+// and may override any of its colors; ok reports whether a theme file exists,
+// without one the dark theme is returned.
+func LoadTheme() (theme model.Theme, ok bool, err error) {
+	themePath, err := GetThemePath()
+	if err != nil {
+		return model.DarkTheme(), false, err
+	}
+
+	data, err := os.ReadFile(themePath)
+	if os.IsNotExist(err) {
+		return model.DarkTheme(), false, nil
+	}
+	if err != nil {
+		return model.DarkTheme(), false, fmt.Errorf("failed to read theme file: %w", err)
+	}
+
+	// Start from the named built-in theme so unset colors keep its values
+	var named struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &named); err != nil {
+		return model.DarkTheme(), false, fmt.Errorf("failed to parse theme file: %w", err)
+	}
+	theme, err = model.BuiltinTheme(named.Name)
+	if err != nil {
+		return theme, false, err
+	}
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return model.DarkTheme(), false, fmt.Errorf("failed to parse theme file: %w", err)
+	}
+
+	return theme, true, nil
+}
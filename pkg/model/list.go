@@ -0,0 +1,132 @@
+package model
+
+import "strings"
+
+// GroupedList reports whether the command list is drawn under category
+// headers: grouping is on and the list isn't limited to one category
+func (m Model) GroupedList() bool {
+	return m.GroupByCategory && (m.ActiveCategory == "" || m.ActiveCategory == "All")
+}
+
+// StartsGroup reports whether a category header is drawn above visible
+// command i in a list window beginning at start. The first row of the window
+// always gets one so its category stays visible while scrolling.
+func (m Model) StartsGroup(i, start int) bool {
+	if !m.GroupedList() {
+		return false
+	}
+	return i == start || m.VisibleCommands[i].Category != m.VisibleCommands[i-1].Category
+}
+
+// RowLines returns how many lines visible command i takes in the list when it
+// isn't selected: two in the detailed list when it has a description
+func (m Model) RowLines(i int) int {
+	if m.DetailedList && m.VisibleCommands[i].Description != "" {
+		return 2
+	}
+	return 1
+}
+
+// ListWindowEnd returns the index after the last command that fits in rows
+// lines when the list window begins at start; category headers take a line
+// each. At least one command is shown.
+func (m Model) ListWindowEnd(start, rows int) int {
+	end := start
+	for end < len(m.VisibleCommands) {
+		if m.StartsGroup(end, start) {
+			rows--
+		}
+		if rows -= m.RowLines(end); rows < 0 {
+			break
+		}
+		end++
+	}
+	return max(end, min(start+1, len(m.VisibleCommands)))
+}
+
+// ListWindow returns the range of visible commands shown in rows lines,
+// starting from ListOffset but moved only as far as needed to keep the
+// selected command on screen and the window filled
+func (m Model) ListWindow(rows int) (start, end int) {
+	start = max(min(m.ListOffset, len(m.VisibleCommands)-1), 0)
+	if m.SelectedIndex < start {
+		start = m.SelectedIndex
+	}
+	for start < m.SelectedIndex && m.ListWindowEnd(start, rows) <= m.SelectedIndex {
+		start++
+	}
+	// Don't leave empty lines below the last command
+	for start > 0 && m.ListWindowEnd(start-1, rows) == len(m.VisibleCommands) {
+		start--
+	}
+	return start, m.ListWindowEnd(start, rows)
+}
+
+// minListRows is the fewest lines the command list gets, however little room
+// is left
+const minListRows = 3
+
+// ListTop returns the screen line the command list starts on in the main
+// view: below the title, the categories, the tags and filter lines and the
+// blank lines between them
+func (m Model) ListTop() int {
+	top := 4
+	if m.SortMode != SortConfig {
+		top += 2 // The sort label is padded above and below
+	}
+	if len(m.ActiveTags) > 0 {
+		top++
+	}
+	if m.CurrentMode == ModeFilterInput || m.FilterText != "" {
+		top += 2
+	}
+	return top
+}
+
+// ListRows returns how many lines the command list gets in the main view:
+// the height minus everything the view draws around it. Keep it in step with
+// view.renderMain.
+func (m Model) ListRows() int {
+	// The selected command's command line and description, the "n more"
+	// lines above and below the list and the help footer, padded and set off
+	// by two blank lines
+	chrome := m.ListTop() + 2 + 2 + 5
+
+	// Status lines take the place of a blank line above the help
+	status := 0
+	for _, message := range []string{m.Error, m.Info, m.Warning} {
+		if message != "" {
+			status += strings.Count(message, "\n") + 1
+		}
+	}
+	if m.UnsavedChanges {
+		status++
+	}
+	return max(m.Height-chrome-status-m.promptLines(), minListRows)
+}
+
+// promptLines returns how many more lines than the plain help footer the
+// prompt of the current mode takes below the list
+func (m Model) promptLines() int {
+	switch {
+	case m.CurrentMode == ModeConfirm && m.ConfirmBatch:
+		// A bordered box listing the marked commands that ask before running
+		asking := 0
+		for _, cmd := range m.AllCommands {
+			if m.Marked[cmd.ID] && cmd.AsksBeforeRun(m.Settings) {
+				asking++
+			}
+		}
+		return asking + 4
+	case m.CurrentMode == ModeConfirm && m.ConfirmCommand != nil:
+		// A bordered box with the name and the command line
+		return strings.Count(m.ConfirmCommand.Command, "\n") + 5
+	case m.CurrentMode == ModeWatchInterval && m.WatchCommand != nil:
+		return 1
+	case m.CurrentMode == ModePlaceholder && m.PlaceholderCommand != nil:
+		return 1 + len(m.Settings.PlaceholderHistory[m.PlaceholderNames[len(m.PlaceholderValues)]])
+	case m.CurrentMode == ModeTagPicker:
+		return max(len(m.TagOptions), 1)
+	}
+	return 0
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Fold lowercases s and strips its accents ("Café" becomes "cafe") so text can
+// be matched ignoring both
+func Fold(s string) string {
+	var sb strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// FoldEqual reports whether a and b are the same text ignoring case and
+// accents
+func FoldEqual(a, b string) bool {
+	return Fold(a) == Fold(b)
+}
+
+// FuzzyMatch matches pattern against text ignoring case and accents. A contiguous
+// match scores higher than a scattered subsequence, and matches at the start
+// of a word score higher still. It returns 0 and no positions when pattern
+// doesn't match; positions are rune indexes into text, covering the
+// combining marks of the matched characters when text is decomposed.
+func FuzzyMatch(text, pattern string) (int, []int) {
+	t, index := foldRunes(text)
+	p := []rune(Fold(pattern))
+	if len(p) == 0 || len(p) > len(t) {
+		return 0, nil
+	}
+
+	// Contiguous substring
+	for i := 0; i+len(p) <= len(t); i++ {
+		if runesEqual(t[i:i+len(p)], p) {
+			score := 100 - min(i, 20)
+			if i == 0 || !isWordRune(t[i-1]) {
+				score += 20
+			}
+			positions := make([]int, len(p))
+			for j := range p {
+				positions[j] = i + j
+			}
+			return score, textPositions(text, index, positions)
+		}
+	}
+
+	// Subsequence, e.g. "dsk" in "disk"; every skipped rune costs a point
+	positions := make([]int, 0, len(p))
+	j := 0
+	for i := 0; i < len(t) && j < len(p); i++ {
+		if t[i] == p[j] {
+			positions = append(positions, i)
+			j++
+		}
+	}
+	if j < len(p) {
+		return 0, nil
+	}
+	gaps := positions[len(positions)-1] - positions[0] + 1 - len(p)
+	return max(50-gaps, 1), textPositions(text, index, positions)
+}
+
+// foldRunes folds s like Fold, combining marks dropped whether s spells
+// accents precomposed ("é") or decomposed ("e\u0301"). With each folded
+// rune it returns the index of the rune of s it comes from.
+func foldRunes(s string) ([]rune, []int) {
+	var folded []rune
+	var index []int
+	for i, r := range []rune(s) {
+		for _, d := range norm.NFD.String(string(r)) {
+			if !unicode.Is(unicode.Mn, d) {
+				folded = append(folded, unicode.ToLower(d))
+				index = append(index, i)
+			}
+		}
+	}
+	return folded, index
+}
+
+// textPositions maps positions in the folded text back to rune indexes into
+// text, adding the combining marks that follow each matched character so a
+// highlight doesn't split a character from its accent
+func textPositions(text string, index, positions []int) []int {
+	runes := []rune(text)
+	var mapped []int
+	for _, p := range positions {
+		i := index[p]
+		if len(mapped) > 0 && mapped[len(mapped)-1] >= i {
+			continue // Another part of the same character
+		}
+		mapped = append(mapped, i)
+		for i+1 < len(runes) && unicode.Is(unicode.Mn, runes[i+1]) {
+			i++
+			mapped = append(mapped, i)
+		}
+	}
+	return mapped
+}
+
+// runesEqual reports whether a and b hold the same runes
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+// isWordRune reports whether r is part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
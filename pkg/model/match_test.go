@@ -0,0 +1,84 @@
+package model
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Deploy", "deploy"},
+		{"Café", "cafe"},
+		{"Café", "cafe"},
+		{"ÉCOLE", "ecole"},
+		{"ÉCOLE", "ecole"},
+		{"Straße", "straße"},
+		{"日本語", "日本語"},
+	}
+	for _, tt := range tests {
+		if got := Fold(tt.in); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFoldEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"café", "CAFE", true},
+		{"café", "café", true},
+		{"Résumé", "RÉSUMÉ", true},
+		{"cafe", "cafes", false},
+		{"café", "cafè", true},
+	}
+	for _, tt := range tests {
+		if got := FoldEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("FoldEqual(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		text, pattern string
+		wantPositions []int // nil when there is no match
+	}{
+		{"plain", "deploy", "dep", []int{0, 1, 2}},
+		{"case", "Deploy", "DEP", []int{0, 1, 2}},
+		{"precomposed text", "café", "cafe", []int{0, 1, 2, 3}},
+		{"precomposed pattern", "cafe", "café", []int{0, 1, 2, 3}},
+		{"decomposed text", "café", "café", []int{0, 1, 2, 3, 4}},
+		{"decomposed text, plain pattern", "café bar", "cafe", []int{0, 1, 2, 3, 4}},
+		{"decomposed pattern", "café", "café", []int{0, 1, 2, 3}},
+		{"after a decomposed accent", "école", "co", []int{2, 3}},
+		{"uppercase decomposed", "ÉCOLE", "école", []int{0, 1, 2, 3, 4, 5}},
+		{"subsequence through accents", "résumé", "rsm", []int{0, 3, 5}},
+		{"no match", "café", "cafes", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, positions := FuzzyMatch(tt.text, tt.pattern)
+			if (score > 0) != (tt.wantPositions != nil) {
+				t.Fatalf("FuzzyMatch(%q, %q) score = %d, want a match: %v", tt.text, tt.pattern, score, tt.wantPositions != nil)
+			}
+			if !slices.Equal(positions, tt.wantPositions) {
+				t.Errorf("FuzzyMatch(%q, %q) positions = %v, want %v", tt.text, tt.pattern, positions, tt.wantPositions)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatchScoresDecomposedLikePrecomposed(t *testing.T) {
+	for _, pattern := range []string{"cafe", "café", "cf"} {
+		precomposed, _ := FuzzyMatch("le café", pattern)
+		decomposed, _ := FuzzyMatch("le café", pattern)
+		if precomposed != decomposed {
+			t.Errorf("pattern %q: score %d for decomposed text, want %d as for precomposed", pattern, decomposed, precomposed)
+		}
+	}
+}
@@ -1,25 +1,73 @@
 package model
 
 import (
+	"os"
+	"strconv"
 	"strings"
 	"time"
 )
 
-// Command represents a shell command with metadata
+// Command represents a shell command with metadata. JSON uses the field
+// names as keys; the yaml tags give commands.yaml readable snake_case keys.
 type Command struct {
-	ID          string    // Unique identifier
-	Name        string    // Display name
-	Command     string    // The actual command to execute
-	Category    string    // Category for organization
-	Description string    // Description of what the command does
-	Tags        []string  // Tags for filtering
-	LastRun     time.Time // When the command was last executed
+	ID          string   `yaml:"id"`                     // Unique identifier
+	Name        string   `yaml:"name"`                   // Display name
+	Command     string   `yaml:"command"`                // The actual command to execute
+	PreCommand  string   `yaml:"pre_command,omitempty"`  // Run before Command with the same settings; Command is skipped when it fails
+	PostCommand string   `yaml:"post_command,omitempty"` // Run after Command (e.g. cleanup) with the same settings
+	Category    string   `yaml:"category,omitempty"`     // Category for organization
+	Description string   `yaml:"description,omitempty"`  // Description of what the command does
+	Tags        []string `yaml:"tags,omitempty,flow"`    // Tags for filtering
+	Aliases     []string `yaml:"aliases,omitempty,flow"` // Short names for `go-recipe run` and the filter; unique across commands
+	Protected   bool     `yaml:"protected,omitempty"`    // Guards against accidental edit/delete
+	Favorite    bool     `yaml:"favorite,omitempty"`     // Listed under the Favorites pseudo-category
+	Confirm     bool     `yaml:"confirm,omitempty"`      // Ask for confirmation before every run (for destructive commands)
+	// Run stats, filled in from state.json; saving the commands file leaves them out
+	LastRun  time.Time `json:",omitzero" yaml:"last_run,omitempty"`   // When the command was last executed
+	RunCount int       `json:",omitempty" yaml:"run_count,omitempty"` // How many times the command has been run from go-recipe
+	LastExit *int      `json:",omitempty" yaml:"last_exit,omitempty"` // Exit code of the last finished run; nil until one finishes
 	// Working directory behavior
-	WorkingDirMode string // current|home|absolute (empty treated as current)
-	WorkingDirPath string // used when WorkingDirMode == "absolute"; supports ~, $HOME, ${cwd}
+	WorkingDirMode   string `yaml:"working_dir_mode,omitempty"`   // current|home|absolute|relative (empty treated as current)
+	WorkingDirPath   string `yaml:"working_dir_path,omitempty"`   // used when WorkingDirMode is absolute or relative (to the project root); supports ~, $HOME, ${cwd}
+	CreateWorkingDir bool   `yaml:"create_working_dir,omitempty"` // when true, a missing working directory path is created instead of failing
 	// Execution behavior
-	UseShell    bool // when true, execute via shell (bash -lc on Unix; cmd /c on Windows)
-	Interactive bool // when true, run attached (for interactive/long-running commands)
+	UseShell           bool   `yaml:"use_shell,omitempty"`             // when true, execute via shell (bash -lc on Unix; cmd /c on Windows)
+	Shell              string `yaml:"shell,omitempty"`                 // shell used instead of $SHELL when running via the shell, e.g. zsh or sh
+	Interactive        bool   `yaml:"interactive,omitempty"`           // when true, run attached (for interactive/long-running commands)
+	OpenOutputInEditor bool   `yaml:"open_output_in_editor,omitempty"` // when true, open the captured output in $PAGER/$EDITOR after the run
+	TimeoutSeconds     int    `yaml:"timeout_seconds,omitempty"`       // when > 0, kill the command after this many seconds (not applied to interactive runs)
+	Retries            int    `yaml:"retries,omitempty"`               // how many times a failed run is retried (not applied to interactive runs)
+	RetryDelaySeconds  int    `yaml:"retry_delay_seconds,omitempty"`   // pause between retries
+	// Environment
+	EnvFile string   `yaml:"env_file,omitempty"` // optional dotenv file (KEY=VALUE lines) applied before running; supports ~, $HOME, ${cwd}
+	Env     []string `yaml:"env,omitempty"`      // KEY=VALUE pairs applied after EnvFile, so they take precedence
+	// Input
+	StdinFile string `yaml:"stdin_file,omitempty"` // file whose contents are piped to the command's stdin; supports ~, $HOME, ${cwd}
+	// Output
+	Redact    []string `yaml:"redact,omitempty"`     // regular expressions whose matches are masked in displayed and saved output
+	ColorMode string   `yaml:"color_mode,omitempty"` // auto|always|never (empty treated as auto); always runs in a pseudo-terminal, never strips colors
+}
+
+// UsesWorkingDirPath reports whether WorkingDirPath applies: the working
+// directory mode is absolute or relative
+func (c Command) UsesWorkingDirPath() bool {
+	mode := strings.ToLower(strings.TrimSpace(c.WorkingDirMode))
+	return mode == "absolute" || mode == "relative"
+}
+
+// AsksBeforeRun reports whether running the command shows a yes/no prompt
+// first: it is marked Confirm or confirm_before_run is set
+func (c Command) AsksBeforeRun(settings Settings) bool {
+	return settings.ConfirmBeforeRun || c.Confirm
+}
+
+// FavoritesCategory is the pseudo-category listing favorite commands
+const FavoritesCategory = "Favorites"
+
+// PseudoCategory reports whether category is one of the list's built-in views,
+// "All" or "Favorites", rather than a category commands are filed under
+func PseudoCategory(category string) bool {
+	return category == "All" || category == FavoritesCategory
 }
 
 // FormField represents a field in the add/edit form
@@ -28,16 +76,89 @@ type FormField int
 const (
 	FieldName FormField = iota
 	FieldCommand
+	FieldPreCommand
+	FieldPostCommand
 	FieldCategory
 	FieldDescription
 	FieldTags
+	FieldAliases
 	FieldWorkingDirMode
 	FieldWorkingDirPath
+	FieldCreateWorkingDir
 	FieldUseShell
+	FieldShell
 	FieldInteractive
+	FieldConfirm
+	FieldEnvFile
+	FieldEnv
+	FieldStdinFile
+	FieldOpenOutputInEditor
+	FieldRedact
+	FieldColorMode
+	FieldTimeoutSeconds
+	FieldRetries
+	FieldRetryDelaySeconds
 	FieldCount // Total number of fields
 )
 
+// formFieldNames holds the display name of each form field, indexed by FormField
+var formFieldNames = [FieldCount]string{
+	"Name",
+	"Command",
+	"PreCommand",
+	"PostCommand",
+	"Category",
+	"Description",
+	"Tags",
+	"Aliases",
+	"WorkingDirMode",
+	"WorkingDirPath",
+	"CreateWorkingDir",
+	"UseShell",
+	"Shell",
+	"Interactive",
+	"Confirm",
+	"EnvFile",
+	"Env",
+	"StdinFile",
+	"OpenOutputInEditor",
+	"Redact",
+	"ColorMode",
+	"TimeoutSeconds",
+	"Retries",
+	"RetryDelaySeconds",
+}
+
+// String returns the display name of the form field
+func (f FormField) String() string {
+	if f < 0 || f >= FieldCount {
+		return ""
+	}
+	return formFieldNames[f]
+}
+
+// fieldChoices lists the values of form fields that are picked from a fixed set
+// instead of typed
+var fieldChoices = map[FormField][]string{
+	FieldWorkingDirMode: {"current", "home", "absolute", "relative"},
+}
+
+// Choices returns the values the field cycles through, or nil for fields
+// edited as free text
+func (f FormField) Choices() []string {
+	return fieldChoices[f]
+}
+
+// ParseFormField looks up a form field by its display name (case-insensitive)
+func ParseFormField(name string) (FormField, bool) {
+	for i, fieldName := range formFieldNames {
+		if strings.EqualFold(fieldName, strings.TrimSpace(name)) {
+			return FormField(i), true
+		}
+	}
+	return 0, false
+}
+
 // AppMode represents the different text input modes
 type AppMode int
 
@@ -45,44 +166,210 @@ const (
 	ModeNormal AppMode = iota
 	ModeFilterInput
 	ModeFormEdit
+	ModePinSlot
+	ModeConfirm
+	ModeWatchInterval
+	ModePalette
+	ModePlaceholder
+	ModeTagPicker
+	ModeConfirmQuit
 )
 
+// LogFile describes a background run log under ~/.go-recipe/logs
+type LogFile struct {
+	Name    string    // File name
+	Path    string    // Full path
+	ModTime time.Time // Last modification time
+	Size    int64     // Size in bytes
+}
+
+// ExecutionRecord is the stored result of a command's last run, kept under
+// ~/.go-recipe/results when save_results is enabled
+type ExecutionRecord struct {
+	CommandID string    `json:"command_id"`
+	Command   string    `json:"command"`         // Command line that ran
+	Output    string    `json:"output"`          // Output, possibly truncated
+	Truncated bool      `json:"truncated"`       // Whether the start of the output was dropped
+	ExitCode  int       `json:"exit_code"`       // Exit code of the run
+	Error     string    `json:"error,omitempty"` // Error message, if the run failed
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// HistoryEntry is one line of the execution history in history.jsonl
+type HistoryEntry struct {
+	CommandID  string    `json:"command_id"`
+	Name       string    `json:"name"` // Command name at the time of the run
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	ExitCode   int       `json:"exit_code"`
+	DurationMs int64     `json:"duration_ms"` // EndTime - StartTime in milliseconds
+}
+
+// BackgroundTask is a command started in background mode during this session
+type BackgroundTask struct {
+	ID       int       // Sequence number, starting at 1
+	Name     string    // Command name
+	LogPath  string    // Log file the output is written to
+	Started  time.Time // Start time
+	Finished time.Time // End time, zero while running
+	Done     bool      // Whether the command finished
+	ExitCode int       // Exit code once done
+}
+
+// SortMode is the order of the command list
+type SortMode int
+
+const (
+	SortConfig   SortMode = iota // Order of the commands file
+	SortName                     // Alphabetical by name
+	SortRecent                   // Most recently run first
+	SortFrequent                 // Most often run first
+	SortModeCount
+)
+
+// sortModeNames holds the display name of each sort mode, indexed by SortMode
+var sortModeNames = [SortModeCount]string{"config", "name", "recent", "frequent"}
+
+// String returns the display name of the sort mode
+func (s SortMode) String() string {
+	if s < 0 || s >= SortModeCount {
+		return ""
+	}
+	return sortModeNames[s]
+}
+
+// ParseSortMode looks up a sort mode by its display name (case-insensitive)
+func ParseSortMode(name string) (SortMode, bool) {
+	for i, modeName := range sortModeNames {
+		if strings.EqualFold(modeName, strings.TrimSpace(name)) {
+			return SortMode(i), true
+		}
+	}
+	return SortConfig, false
+}
+
 // Model represents the application state
 type Model struct {
 	AllCommands     []Command // All available commands
 	VisibleCommands []Command // Commands after filtering
 	Categories      []string  // Available categories
 	SelectedIndex   int       // Currently selected command index
+	ListOffset      int       // Index of the first command shown when the list overflows the screen
 	FilterText      string    // Current filter text
 	ActiveCategory  string    // Currently selected category
+	ActiveTags      []string  // Tags the list is filtered by
+	MatchAllTags    bool      // Whether commands need all ActiveTags rather than any
+	SortMode        SortMode  // Order of the visible commands
+	GroupByCategory bool      // Whether the "All" list is grouped under category headers
+	DetailedList    bool      // Whether every row shows its description, not only the selected one
 
 	// UI State
-	RunInBackground      bool     // Whether to run commands in background
-	ShowHelp             bool     // Whether help is being displayed
-	ShowForm             bool     // Whether add/edit form is displayed
-	Executing            bool     // Whether a command is currently executing
-	ExecutionOutput      string   // Output of the last executed command
-	ExecutingCommand     *Command // Currently executing command
-	OutputScrollPosition int      // Scroll position for command output
-	ExecutionLogPath     string   // Temp log file path for streaming
-	ExecutionLogOffset   int64    // Read offset for streaming
-	ExecutionCancel      func()   // Cancel function to stop running process
-	ExecutingAnimIndex   int      // Spinner frame index while streaming
-	Spinning             bool     // Whether to show spinner in ExecutionOutput
-	StreamedOutput       string   // Aggregated output read so far (without spinner)
+	RunInBackground      bool          // Whether to run commands in background
+	ShowHelp             bool          // Whether help is being displayed
+	ShowForm             bool          // Whether add/edit form is displayed
+	Executing            bool          // Whether a command is currently executing
+	ExecutionOutput      string        // Output of the last executed command
+	AlternateOutput      string        // ExecutionOutput with stdout and stderr the other way (combined or separated); empty when there is no stderr
+	SeparateStderr       bool          // Whether finished runs show stderr in its own section
+	ExecutingCommand     *Command      // Currently executing command
+	OutputScrollPosition int           // Scroll position for command output
+	FollowOutput         bool          // Keep the view pinned to the bottom as output streams in
+	ExecutionLogPath     string        // Temp log file path for streaming
+	ExecutionLogOffset   int64         // Read offset for streaming
+	ExecutionLogFile     *os.File      // Streaming log kept open between polls; nil until the first poll
+	ExecutionTempLog     string        // Temp log of the foreground run shown, removed when the execution view is left
+	PTY                  *os.File      // Pseudo-terminal of an interactive command running in the execution view; keys are written to it
+	Screen               *Screen       // What the command in PTY has drawn, interpreted like a terminal; nil otherwise
+	ViewingLastResult    bool          // Whether the execution view shows a stored result instead of a run
+	ViewingDryRun        bool          // Whether the execution view shows what a run would do instead of a run
+	ExecutionStart       time.Time     // When the foreground command started; zero when not timed
+	ExecutionElapsed     time.Duration // Time taken so far, or in total once finished
+	ExecutionCancel      func()        // Cancel function to stop running process
+	ExecutingAnimIndex   int           // Spinner frame index while streaming
+	Spinning             bool          // Whether to show spinner in ExecutionOutput
+	StreamedOutput       OutputBuffer  // Last lines of the output read so far (without spinner), bounded by output_max_lines
+
+	// Watch mode: re-run the executing command on an interval
+	Watching      bool          // Whether the executing command is being re-run
+	WatchInterval time.Duration // Delay between watch runs
+	WatchLastRun  time.Time     // When the last watch run finished
+	WatchID       int           // Identifies the current watch so stale ticks are ignored
+	WatchCancel   func()        // Kills the watch run in progress, if any
+
+	// Line selection in the execution view
+	SelectionActive bool // Whether a line range is being selected
+	SelectionAnchor int  // Output line where the selection started
+	SelectionCursor int  // Output line at the moving end of the selection
 
 	// Form state for adding/editing commands
 	FormCommand      Command   // Command being edited in form
 	ActiveFormField  FormField // Currently active form field
 	EditingFormField bool      // Whether we're currently editing a form field
 	FormInputBuffer  string    // Buffer for text input
+	FormCursor       int       // Rune position of the cursor in FormInputBuffer
+	FormPreview      string    // Live preview of the field being edited (e.g. resolved path)
+	FormPreviewError bool      // Whether FormPreview describes a problem
 
 	// Mode state for different input modes
-	CurrentMode AppMode // Current app mode
-	InputBuffer string  // Text input buffer for various modes
+	CurrentMode    AppMode  // Current app mode
+	InputBuffer    string   // Text input buffer for various modes
+	ConfirmCommand *Command // Command awaiting confirmation in ModeConfirm
+	ConfirmBatch   bool     // Whether ModeConfirm asks before running the marked commands
+	QuitAfterRun   bool     // Quit as soon as the cancelled foreground command has exited
+	WatchCommand   *Command // Command awaiting an interval in ModeWatchInterval
+	UnprotectID    string   // Command whose unprotect is awaiting a second L press
+	DeletedCommand *Command // Last deleted command, restored with u
+	DeletedIndex   int      // Position of DeletedCommand in AllCommands before the delete
+
+	// Placeholder prompt (ModePlaceholder) state
+	PlaceholderCommand *Command          // Command whose {{name}} placeholders are being filled in
+	PlaceholderNames   []string          // Placeholders in order of appearance
+	PlaceholderValues  map[string]string // Values entered so far
+	PlaceholderPick    int               // Selected history suggestion for the current placeholder, -1 if typed
+	PlaceholderDryRun  bool              // Whether the filled-in command is shown as a dry run instead of run
+	PlaceholderWatch   bool              // Whether the filled-in command is watched instead of run once
+
+	// Batch run of the commands marked with space
+	Marked      map[string]bool // IDs of the marked commands
+	BatchQueue  []Command       // Commands of the running batch still to run
+	BatchIndex  int             // 1-based position of the current command in the batch
+	BatchTotal  int             // Number of commands in the batch, 0 outside a batch
+	BatchOutput string          // Formatted output of the batch commands that already finished
+
+	// Tag picker (ModeTagPicker) state
+	TagOptions []string // All tags, sorted
+	TagIndex   int      // Highlighted tag
+
+	// Background tasks started in this session
+	Tasks     []BackgroundTask // Oldest first
+	ShowTasks bool             // Whether the task list is displayed
+	TaskIndex int              // Selected task
+
+	// Category manager
+	ShowCategories   bool   // Whether the category manager is displayed
+	CategoryIndex    int    // Selected category
+	RenamingCategory bool   // Whether InputBuffer holds a new name for the selected category
+	DeleteCategory   string // Category waiting for a second d to be deleted
+
+	// Background log browser
+	ShowLogs bool      // Whether the log list is displayed
+	LogFiles []LogFile // Log files, most recently modified first
+	LogIndex int       // Selected log file
+
+	// Command palette (ModePalette) state, kept apart from the filter input
+	PaletteQuery   string    // Text typed into the palette
+	PaletteResults []Command // Commands matching the palette query
+	PaletteIndex   int       // Selected palette result
+
+	// User preferences loaded from the settings file
+	Settings Settings
 
 	// Error state
-	Error string // Current error message, if any
+	Error          string // Current error message, if any
+	Info           string // Current status message (confirmations, hints), shown apart from errors
+	UnsavedChanges bool   // The last save of commands.json failed; edits exist only in memory
+	Warning        string // Shown in the list view until dismissed with esc, unlike Error
 
 	// Width and height for responsive design
 	Width  int
@@ -110,12 +397,12 @@ func NewModel(runInBackground bool) Model {
 		FormInputBuffer:      "",
 		CurrentMode:          ModeNormal,
 		InputBuffer:          "",
+		Settings:             DefaultSettings(),
 		Error:                "",
 		Width:                80,
 		Height:               24,
 		ExecutingAnimIndex:   0,
 		Spinning:             false,
-		StreamedOutput:       "",
 	}
 }
 
@@ -126,6 +413,10 @@ func (m *Model) GetFormFieldValue(field FormField) string {
 		return m.FormCommand.Name
 	case FieldCommand:
 		return m.FormCommand.Command
+	case FieldPreCommand:
+		return m.FormCommand.PreCommand
+	case FieldPostCommand:
+		return m.FormCommand.PostCommand
 	case FieldCategory:
 		return m.FormCommand.Category
 	case FieldDescription:
@@ -140,20 +431,55 @@ func (m *Model) GetFormFieldValue(field FormField) string {
 			result += tag
 		}
 		return result
+	case FieldAliases:
+		return strings.Join(m.FormCommand.Aliases, ", ")
 	case FieldWorkingDirMode:
 		return m.FormCommand.WorkingDirMode
 	case FieldWorkingDirPath:
 		return m.FormCommand.WorkingDirPath
+	case FieldCreateWorkingDir:
+		if m.FormCommand.CreateWorkingDir {
+			return "true"
+		}
+		return "false"
 	case FieldUseShell:
 		if m.FormCommand.UseShell {
 			return "true"
 		}
 		return "false"
+	case FieldShell:
+		return m.FormCommand.Shell
 	case FieldInteractive:
 		if m.FormCommand.Interactive {
 			return "true"
 		}
 		return "false"
+	case FieldConfirm:
+		if m.FormCommand.Confirm {
+			return "true"
+		}
+		return "false"
+	case FieldEnvFile:
+		return m.FormCommand.EnvFile
+	case FieldEnv:
+		return strings.Join(m.FormCommand.Env, ", ")
+	case FieldStdinFile:
+		return m.FormCommand.StdinFile
+	case FieldOpenOutputInEditor:
+		if m.FormCommand.OpenOutputInEditor {
+			return "true"
+		}
+		return "false"
+	case FieldRedact:
+		return strings.Join(m.FormCommand.Redact, "; ")
+	case FieldColorMode:
+		return m.FormCommand.ColorMode
+	case FieldTimeoutSeconds:
+		return formatCount(m.FormCommand.TimeoutSeconds)
+	case FieldRetries:
+		return formatCount(m.FormCommand.Retries)
+	case FieldRetryDelaySeconds:
+		return formatCount(m.FormCommand.RetryDelaySeconds)
 	default:
 		return ""
 	}
@@ -166,6 +492,10 @@ func (m *Model) SetFormFieldValue(field FormField, value string) {
 		m.FormCommand.Name = value
 	case FieldCommand:
 		m.FormCommand.Command = value
+	case FieldPreCommand:
+		m.FormCommand.PreCommand = value
+	case FieldPostCommand:
+		m.FormCommand.PostCommand = value
 	case FieldCategory:
 		m.FormCommand.Category = value
 	case FieldDescription:
@@ -174,24 +504,102 @@ func (m *Model) SetFormFieldValue(field FormField, value string) {
 		// Split comma-separated tags
 		m.FormCommand.Tags = []string{}
 		if value != "" {
-			tags := []string{}
-			for _, tag := range strings.Split(value, ",") {
-				trimmed := strings.TrimSpace(tag)
-				if trimmed != "" {
-					tags = append(tags, trimmed)
-				}
-			}
-			m.FormCommand.Tags = tags
+			m.FormCommand.Tags = NormalizeTags(strings.Split(value, ","), false)
 		}
+	case FieldAliases:
+		// Aliases are comma-separated like tags
+		m.FormCommand.Aliases = NormalizeTags(strings.Split(value, ","), false)
 	case FieldWorkingDirMode:
 		m.FormCommand.WorkingDirMode = value
 	case FieldWorkingDirPath:
 		m.FormCommand.WorkingDirPath = value
+	case FieldCreateWorkingDir:
+		lv := strings.ToLower(strings.TrimSpace(value))
+		m.FormCommand.CreateWorkingDir = lv == "true" || lv == "1" || lv == "yes" || lv == "y"
 	case FieldUseShell:
 		lv := strings.ToLower(strings.TrimSpace(value))
 		m.FormCommand.UseShell = lv == "true" || lv == "1" || lv == "yes" || lv == "y"
+	case FieldShell:
+		m.FormCommand.Shell = strings.TrimSpace(value)
 	case FieldInteractive:
 		lv := strings.ToLower(strings.TrimSpace(value))
 		m.FormCommand.Interactive = lv == "true" || lv == "1" || lv == "yes" || lv == "y"
+	case FieldConfirm:
+		lv := strings.ToLower(strings.TrimSpace(value))
+		m.FormCommand.Confirm = lv == "true" || lv == "1" || lv == "yes" || lv == "y"
+	case FieldEnvFile:
+		m.FormCommand.EnvFile = strings.TrimSpace(value)
+	case FieldEnv:
+		// Split comma-separated KEY=VALUE pairs; entries are validated on save
+		m.FormCommand.Env = nil
+		for _, pair := range strings.Split(value, ",") {
+			if pair = strings.TrimSpace(pair); pair != "" {
+				m.FormCommand.Env = append(m.FormCommand.Env, pair)
+			}
+		}
+	case FieldStdinFile:
+		m.FormCommand.StdinFile = strings.TrimSpace(value)
+	case FieldOpenOutputInEditor:
+		lv := strings.ToLower(strings.TrimSpace(value))
+		m.FormCommand.OpenOutputInEditor = lv == "true" || lv == "1" || lv == "yes" || lv == "y"
+	case FieldRedact:
+		// Split ";"-separated patterns (commas are common inside regexps)
+		m.FormCommand.Redact = nil
+		for _, p := range strings.Split(value, ";") {
+			if p = strings.TrimSpace(p); p != "" {
+				m.FormCommand.Redact = append(m.FormCommand.Redact, p)
+			}
+		}
+	case FieldColorMode:
+		m.FormCommand.ColorMode = strings.ToLower(strings.TrimSpace(value))
+	case FieldTimeoutSeconds:
+		// Empty means no timeout
+		m.FormCommand.TimeoutSeconds = parseCount(value)
+	case FieldRetries:
+		m.FormCommand.Retries = parseCount(value)
+	case FieldRetryDelaySeconds:
+		m.FormCommand.RetryDelaySeconds = parseCount(value)
+	}
+}
+
+// formatCount shows a non-negative number field, leaving 0 empty
+func formatCount(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// parseCount reads a non-negative number field; empty means 0 and anything
+// unparsable is flagged as -1 for validation on save
+func parseCount(value string) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+		return n
+	}
+	return -1
+}
+
+// NormalizeTags trims tags, drops empty ones and removes case-insensitive
+// duplicates, keeping the first occurrence. When lowercase is true, all tags
+// are lowercased as well.
+func NormalizeTags(tags []string, lowercase bool) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if lowercase {
+			tag = strings.ToLower(tag)
+		}
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
 	}
+	return result
 }
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultOutputMaxLines is how many lines of a command's output are kept when
+// the output_max_lines setting isn't set
+const DefaultOutputMaxLines = 10000
+
+// MaxLineBytes is the longest line an OutputBuffer keeps; longer lines, like
+// the output of a command that never prints a newline, are split into lines
+// of this length
+const MaxLineBytes = 64 * 1024
+
+// OutputBuffer keeps the last lines written to it in a ring: once it holds its
+// maximum number of lines, every new line replaces the oldest one, so a
+// command's output takes bounded memory however much it prints
+type OutputBuffer struct {
+	lines   []string // Complete lines; once full, lines[start] is the oldest
+	start   int      // Index of the oldest line when the ring is full
+	partial string   // Text after the last newline
+	max     int      // Maximum number of complete lines kept
+	dropped int      // Number of lines replaced so far
+}
+
+// NewOutputBuffer returns an empty buffer keeping at most maxLines lines; 0 or
+// less uses DefaultOutputMaxLines
+func NewOutputBuffer(maxLines int) OutputBuffer {
+	if maxLines <= 0 {
+		maxLines = DefaultOutputMaxLines
+	}
+	return OutputBuffer{max: maxLines}
+}
+
+// Write appends p, which may end in the middle of a line
+func (b *OutputBuffer) Write(p []byte) (int, error) {
+	b.WriteString(string(p))
+	return len(p), nil
+}
+
+// WriteString appends s, which may end in the middle of a line
+func (b *OutputBuffer) WriteString(s string) {
+	if b.max <= 0 {
+		b.max = DefaultOutputMaxLines
+	}
+	lines := strings.Split(b.partial+s, "\n")
+	b.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		for len(line) > MaxLineBytes {
+			i := lineCut(line)
+			b.addLine(line[:i])
+			line = line[i:]
+		}
+		b.addLine(line)
+	}
+	for len(b.partial) > MaxLineBytes {
+		i := lineCut(b.partial)
+		b.addLine(b.partial[:i])
+		b.partial = b.partial[i:]
+	}
+}
+
+// addLine appends a complete line, replacing the oldest once the ring is full
+func (b *OutputBuffer) addLine(line string) {
+	if len(b.lines) < b.max {
+		b.lines = append(b.lines, line)
+		return
+	}
+	b.lines[b.start] = line
+	b.start = (b.start + 1) % b.max
+	b.dropped++
+}
+
+// lineCut returns where a line longer than MaxLineBytes is split, at the
+// start of a character
+func lineCut(line string) int {
+	i := MaxLineBytes
+	for i > 0 && !utf8.RuneStart(line[i]) {
+		i--
+	}
+	if i == 0 {
+		return MaxLineBytes
+	}
+	return i
+}
+
+// String returns the kept output, oldest line first
+func (b OutputBuffer) String() string {
+	var sb strings.Builder
+	for i := range b.lines {
+		sb.WriteString(b.lines[(b.start+i)%len(b.lines)])
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(b.partial)
+	return sb.String()
+}
+
+// Dropped returns how many of the oldest lines were replaced by newer ones
+func (b OutputBuffer) Dropped() int {
+	return b.dropped
+}
+
+// MaxLines returns the number of lines the buffer keeps
+func (b OutputBuffer) MaxLines() int {
+	return b.max
+}
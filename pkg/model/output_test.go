@@ -0,0 +1,38 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestOutputBufferKeepsTheLastLines(t *testing.T) {
+	buf := NewOutputBuffer(3)
+	buf.WriteString("one\ntwo\nthr")
+	buf.WriteString("ee\nfour\nfive")
+	if got, want := buf.String(), "two\nthree\nfour\nfive"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if buf.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", buf.Dropped())
+	}
+}
+
+func TestOutputBufferSplitsLongLines(t *testing.T) {
+	buf := NewOutputBuffer(2)
+	// A progress bar that never prints a newline stays bounded
+	for range 100 {
+		buf.WriteString(strings.Repeat("é", MaxLineBytes/4))
+	}
+	if got := len(buf.String()); got > 3*MaxLineBytes+2 {
+		t.Errorf("the buffer holds %d bytes, want at most its lines", got)
+	}
+	if buf.Dropped() == 0 {
+		t.Error("no lines were dropped")
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if !utf8.ValidString(line) {
+			t.Errorf("a line was split inside a character: %q...", line[:8])
+		}
+	}
+}
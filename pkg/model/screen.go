@@ -0,0 +1,391 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+	"github.com/mattn/go-runewidth"
+)
+
+// Screen is the terminal an interactive command draws on when it runs in a
+// pseudo-terminal inside the execution view. Output written to it is
+// interpreted the way a terminal would: cursor movement, erasing, scrolling
+// and the alternate screen full-screen programs (htop, vim, top) switch to,
+// so the view can show what the command actually drew.
+type Screen struct {
+	main, alt *cellbuf.Buffer
+	buf       *cellbuf.Buffer // The screen being drawn on: main or alt
+	parser    *ansi.Parser
+
+	x, y           int
+	savedX, savedY int
+	wrapNext       bool // The last column was written; the next character starts a new line
+	pen            cellbuf.Style
+	top, bottom    int // Scroll region; bottom is exclusive
+	hideCursor     bool
+
+	scrollback OutputBuffer // Lines scrolled off the top of the main screen
+}
+
+// NewScreen returns a blank width x height screen keeping at most
+// maxScrollback lines scrolled off its top; 0 or less uses
+// DefaultOutputMaxLines
+func NewScreen(width, height, maxScrollback int) *Screen {
+	width, height = max(width, 1), max(height, 1)
+	s := &Screen{
+		main:       cellbuf.NewBuffer(width, height),
+		alt:        cellbuf.NewBuffer(width, height),
+		bottom:     height,
+		scrollback: NewOutputBuffer(maxScrollback),
+	}
+	s.buf = s.main
+	s.parser = ansi.NewParser()
+	s.parser.SetHandler(ansi.Handler{
+		Print:     s.print,
+		Execute:   s.execute,
+		HandleCsi: s.handleCSI,
+		HandleEsc: s.handleEsc,
+	})
+	return s
+}
+
+// Write interprets p as terminal output. Sequences split across writes are
+// picked up where the previous write stopped.
+func (s *Screen) Write(p []byte) (int, error) {
+	for _, b := range p {
+		s.parser.Advance(b)
+	}
+	return len(p), nil
+}
+
+// Resize changes the size of the screen, as a pseudo-terminal resize does.
+// The scroll region is reset, and lines the cursor would fall off from are
+// scrolled off the top.
+func (s *Screen) Resize(width, height int) {
+	width, height = max(width, 1), max(height, 1)
+	if s.y >= height {
+		s.scrollUp(s.y - height + 1)
+		s.y = height - 1
+	}
+	s.main.Resize(width, height)
+	s.alt.Resize(width, height)
+	s.top, s.bottom = 0, height
+	s.x = min(s.x, width-1)
+	s.wrapNext = false
+}
+
+// Width returns the number of columns of the screen
+func (s *Screen) Width() int {
+	return s.buf.Width()
+}
+
+// Height returns the number of rows of the screen
+func (s *Screen) Height() int {
+	return s.buf.Height()
+}
+
+// Render returns the rows of the screen with their colors, the cursor shown
+// in reverse video unless the command hid it. There is always one line per
+// row so the screen keeps its layout.
+func (s *Screen) Render() string {
+	if !s.hideCursor {
+		line := s.buf.Line(s.y)
+		if orig := line[s.x]; orig == nil || orig.Width > 0 {
+			cursor := cellbuf.BlankCell.Clone()
+			if orig != nil {
+				cursor = orig.Clone()
+			}
+			cursor.Style.Reverse(true)
+			line[s.x] = cursor
+			defer func() { line[s.x] = orig }()
+		}
+	}
+	lines := make([]string, s.Height())
+	for y := range lines {
+		_, lines[y] = cellbuf.RenderLine(s.buf, y)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// String returns the text on the screen without colors or trailing blank
+// lines
+func (s *Screen) String() string {
+	return plainText(s.buf)
+}
+
+// Transcript returns the text a command left behind: the lines scrolled off
+// the main screen followed by what is still on it. A full-screen program
+// that switched back from the alternate screen leaves only what was there
+// before it started.
+func (s *Screen) Transcript() string {
+	return s.scrollback.String() + plainText(s.main)
+}
+
+// plainText returns the lines of buf without colors or trailing blank lines
+func plainText(buf *cellbuf.Buffer) string {
+	lines := make([]string, buf.Height())
+	for y := range lines {
+		lines[y] = buf.Line(y).String()
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// print writes a character at the cursor and moves past it
+func (s *Screen) print(r rune) {
+	width := runewidth.RuneWidth(r)
+	if width == 0 {
+		// Combining marks join the character before them
+		if c := s.buf.Cell(s.x-1, s.y); c != nil && s.x > 0 && c.Width > 0 {
+			c.Append(r)
+		}
+		return
+	}
+	if s.wrapNext || s.x+width > s.Width() {
+		s.x = 0
+		s.lineFeed()
+	}
+	s.buf.SetCell(s.x, s.y, &cellbuf.Cell{Rune: r, Width: width, Style: s.pen})
+	s.x += width
+	s.wrapNext = false
+	if s.x >= s.Width() {
+		s.x = s.Width() - 1
+		s.wrapNext = true
+	}
+}
+
+// execute handles a control character
+func (s *Screen) execute(b byte) {
+	s.wrapNext = false
+	switch b {
+	case '\r':
+		s.x = 0
+	case '\n', '\v', '\f':
+		s.lineFeed()
+	case '\b':
+		s.x = max(s.x-1, 0)
+	case '\t':
+		s.x = min((s.x/8+1)*8, s.Width()-1)
+	}
+}
+
+// handleEsc handles the two-byte escape sequences programs use to save the
+// cursor and scroll
+func (s *Screen) handleEsc(cmd ansi.Cmd) {
+	s.wrapNext = false
+	switch cmd.Final() {
+	case '7':
+		s.savedX, s.savedY = s.x, s.y
+	case '8':
+		s.restoreCursor()
+	case 'D':
+		s.lineFeed()
+	case 'E':
+		s.x = 0
+		s.lineFeed()
+	case 'M':
+		s.reverseIndex()
+	case 'c':
+		s.reset()
+	}
+}
+
+// handleCSI handles cursor movement, erasing, scrolling, colors and the
+// alternate screen
+func (s *Screen) handleCSI(cmd ansi.Cmd, params ansi.Params) {
+	s.wrapNext = false
+	if cmd.Prefix() == '?' {
+		s.setModes(cmd.Final() == 'h', params)
+		return
+	}
+	if cmd.Prefix() != 0 || cmd.Intermediate() != 0 {
+		return
+	}
+	n := csiCount(params, 0)
+	w, h := s.Width(), s.Height()
+	switch cmd.Final() {
+	case 'A':
+		s.y = max(s.y-n, 0)
+	case 'B', 'e':
+		s.y = min(s.y+n, h-1)
+	case 'C', 'a':
+		s.x = min(s.x+n, w-1)
+	case 'D':
+		s.x = max(s.x-n, 0)
+	case 'E':
+		s.x, s.y = 0, min(s.y+n, h-1)
+	case 'F':
+		s.x, s.y = 0, max(s.y-n, 0)
+	case 'G', '`':
+		s.x = min(n-1, w-1)
+	case 'd':
+		s.y = min(n-1, h-1)
+	case 'H', 'f':
+		s.y, s.x = min(n-1, h-1), min(csiCount(params, 1)-1, w-1)
+	case 'J':
+		s.eraseDisplay(csiParam(params, 0))
+	case 'K':
+		s.eraseLine(csiParam(params, 0))
+	case 'X':
+		s.buf.FillRect(s.blank(), cellbuf.Rect(s.x, s.y, min(n, w-s.x), 1))
+	case '@':
+		s.buf.InsertCell(s.x, s.y, n, s.blank())
+	case 'P':
+		s.buf.DeleteCell(s.x, s.y, n, s.blank())
+	case 'L':
+		if s.y >= s.top && s.y < s.bottom {
+			s.buf.InsertLineRect(s.y, n, s.blank(), s.region())
+			s.x = 0
+		}
+	case 'M':
+		if s.y >= s.top && s.y < s.bottom {
+			s.buf.DeleteLineRect(s.y, n, s.blank(), s.region())
+			s.x = 0
+		}
+	case 'S':
+		s.scrollUp(n)
+	case 'T':
+		s.buf.InsertLineRect(s.top, n, s.blank(), s.region())
+	case 'r':
+		top, bottom := csiCount(params, 0), csiParam(params, 1)
+		if bottom == 0 || bottom > h {
+			bottom = h
+		}
+		if top < bottom {
+			s.top, s.bottom = top-1, bottom
+			s.x, s.y = 0, 0
+		}
+	case 's':
+		s.savedX, s.savedY = s.x, s.y
+	case 'u':
+		s.restoreCursor()
+	case 'm':
+		cellbuf.ReadStyle(params, &s.pen)
+	}
+}
+
+// setModes turns on or off the private modes for the cursor and the
+// alternate screen
+func (s *Screen) setModes(on bool, params ansi.Params) {
+	for i := range params {
+		switch csiParam(params, i) {
+		case 25:
+			s.hideCursor = !on
+		case 47, 1047, 1049:
+			if on && s.buf != s.alt {
+				s.savedX, s.savedY = s.x, s.y
+				s.buf = s.alt
+				s.buf.Clear()
+			} else if !on && s.buf == s.alt {
+				s.buf = s.main
+				s.restoreCursor()
+			}
+		}
+	}
+}
+
+// restoreCursor moves the cursor back where it was saved, within the
+// screen should it have shrunk since
+func (s *Screen) restoreCursor() {
+	s.x, s.y = min(s.savedX, s.Width()-1), min(s.savedY, s.Height()-1)
+}
+
+// lineFeed moves the cursor down a line, scrolling at the bottom of the
+// scroll region
+func (s *Screen) lineFeed() {
+	switch {
+	case s.y == s.bottom-1:
+		s.scrollUp(1)
+	case s.y < s.Height()-1:
+		s.y++
+	}
+}
+
+// reverseIndex moves the cursor up a line, scrolling down at the top of the
+// scroll region
+func (s *Screen) reverseIndex() {
+	switch {
+	case s.y == s.top:
+		s.buf.InsertLineRect(s.top, 1, s.blank(), s.region())
+	case s.y > 0:
+		s.y--
+	}
+}
+
+// scrollUp moves the scroll region up n lines. Lines leaving a main screen
+// scrolled as a whole are kept in the scrollback.
+func (s *Screen) scrollUp(n int) {
+	n = min(n, s.bottom-s.top)
+	if s.buf == s.main && s.top == 0 {
+		for y := range n {
+			s.scrollback.WriteString(s.buf.Line(y).String() + "\n")
+		}
+	}
+	s.buf.DeleteLineRect(s.top, n, s.blank(), s.region())
+}
+
+// eraseDisplay clears below the cursor (0), above it (1) or everything (2, 3)
+func (s *Screen) eraseDisplay(mode int) {
+	w, h := s.Width(), s.Height()
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		s.buf.FillRect(s.blank(), cellbuf.Rect(0, s.y+1, w, h-s.y-1))
+	case 1:
+		s.eraseLine(1)
+		s.buf.FillRect(s.blank(), cellbuf.Rect(0, 0, w, s.y))
+	case 2, 3:
+		s.buf.FillRect(s.blank(), s.buf.Bounds())
+	}
+}
+
+// eraseLine clears the line right of the cursor (0), left of it (1) or all
+// of it (2), the cursor included
+func (s *Screen) eraseLine(mode int) {
+	w := s.Width()
+	switch mode {
+	case 0:
+		s.buf.FillRect(s.blank(), cellbuf.Rect(s.x, s.y, w-s.x, 1))
+	case 1:
+		s.buf.FillRect(s.blank(), cellbuf.Rect(0, s.y, s.x+1, 1))
+	case 2:
+		s.buf.FillRect(s.blank(), cellbuf.Rect(0, s.y, w, 1))
+	}
+}
+
+// reset clears the screen and every mode, as a terminal reset does
+func (s *Screen) reset() {
+	s.buf = s.main
+	s.main.Clear()
+	s.alt.Clear()
+	s.x, s.y, s.savedX, s.savedY = 0, 0, 0, 0
+	s.pen.Reset()
+	s.top, s.bottom = 0, s.Height()
+	s.hideCursor = false
+}
+
+// region returns the rows of the scroll region
+func (s *Screen) region() cellbuf.Rectangle {
+	return cellbuf.Rect(0, s.top, s.Width(), s.bottom-s.top)
+}
+
+// blank returns the cell erased areas are filled with: a space keeping the
+// current background color
+func (s *Screen) blank() *cellbuf.Cell {
+	if s.pen.Bg == nil {
+		return nil
+	}
+	return &cellbuf.Cell{Rune: ' ', Width: 1, Style: cellbuf.Style{Bg: s.pen.Bg}}
+}
+
+// csiParam returns the i-th parameter of a sequence, 0 when it is missing
+func csiParam(params ansi.Params, i int) int {
+	v, _, _ := params.Param(i, 0)
+	return v
+}
+
+// csiCount returns the i-th parameter of a sequence used as a count or
+// position, where a missing or 0 parameter means 1
+func csiCount(params ansi.Params, i int) int {
+	return max(csiParam(params, i), 1)
+}
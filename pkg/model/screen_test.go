@@ -0,0 +1,108 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScreen(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{"lines", []string{"one\r\ntwo\r\n"}, "one\ntwo"},
+		{"long lines wrap", []string{"abcdefghijkl"}, "abcdefghij\nkl"},
+		{"carriage return overwrites", []string{"50%\r100%"}, "100%"},
+		{"backspace moves back", []string{"ab\bc"}, "ac"},
+		{"cursor position", []string{"hello\x1b[1;1HJ"}, "Jello"},
+		{"cursor up and erase line", []string{"one\r\ntwo\x1b[A\r\x1b[Kuno"}, "uno\ntwo"},
+		{"erase to end of line", []string{"abcdef\x1b[4D\x1b[K"}, "ab"},
+		{"clear screen", []string{"old\r\nlines\x1b[2J\x1b[Hnew"}, "new"},
+		{"erase characters", []string{"abcdef\x1b[1;2H\x1b[2X"}, "a  def"},
+		{"delete characters", []string{"abcdef\x1b[1;2H\x1b[2P"}, "adef"},
+		{"insert lines", []string{"one\r\ntwo\x1b[1;1H\x1b[L"}, "\none\ntwo"},
+		{"sequence split across writes", []string{"abc\x1b[", "1;2", "Hx"}, "axc"},
+		{"colors are not text", []string{"\x1b[1;31mred\x1b[0m plain"}, "red plain"},
+		{"wide characters", []string{"日本\x1b[1;3Hx"}, "日x"},
+		{"combining marks", []string{"été"}, "été"},
+		{"alternate screen", []string{"shell\r\n\x1b[?1049h\x1b[Hfullscreen"}, "fullscreen"},
+		{"back from the alternate screen", []string{"shell\r\n\x1b[?1049h\x1b[Hfullscreen\x1b[?1049l$ "}, "shell\n$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScreen(10, 4, 0)
+			for _, w := range tt.writes {
+				_, _ = s.Write([]byte(w))
+			}
+			if got := s.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenScrollback(t *testing.T) {
+	s := NewScreen(10, 3, 0)
+	for _, line := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = s.Write([]byte(line + "\r\n"))
+	}
+	if got, want := s.String(), "4\n5"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := s.Transcript(), "1\n2\n3\n4\n5"; got != want {
+		t.Errorf("Transcript() = %q, want %q", got, want)
+	}
+
+	// Nothing drawn on the alternate screen scrolls into the transcript
+	_, _ = s.Write([]byte("\x1b[?1049h" + strings.Repeat("full\r\n", 10) + "\x1b[?1049l"))
+	if got, want := s.Transcript(), "1\n2\n3\n4\n5"; got != want {
+		t.Errorf("Transcript() after the alternate screen = %q, want %q", got, want)
+	}
+}
+
+func TestScreenScrollRegion(t *testing.T) {
+	// A status line kept at the top while the lines below it scroll
+	s := NewScreen(10, 4, 0)
+	_, _ = s.Write([]byte("status\x1b[2;4r\x1b[2;1Ha\r\nb\r\nc\r\nd"))
+	if got, want := s.String(), "status\nb\nc\nd"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestScreenRender(t *testing.T) {
+	s := NewScreen(10, 4, 0)
+	_, _ = s.Write([]byte("\x1b[31mred\x1b[0m"))
+
+	lines := strings.Split(s.Render(), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Render() = %d lines, want one per row (4)", len(lines))
+	}
+	if !strings.Contains(lines[0], "\x1b[31mred") {
+		t.Errorf("Render() first row = %q, want it red", lines[0])
+	}
+	// The cursor after "red" is shown in reverse video until it is hidden
+	if !strings.Contains(lines[0], "7m ") {
+		t.Errorf("Render() first row = %q, want the cursor shown", lines[0])
+	}
+	_, _ = s.Write([]byte("\x1b[?25l"))
+	if strings.Contains(s.Render(), "7m") {
+		t.Error("Render() shows the cursor after the command hid it")
+	}
+}
+
+func TestScreenResize(t *testing.T) {
+	s := NewScreen(10, 4, 0)
+	_, _ = s.Write([]byte("1\r\n2\r\n3\r\n4"))
+	s.Resize(20, 2)
+	if s.Width() != 20 || s.Height() != 2 {
+		t.Fatalf("size = %dx%d, want 20x2", s.Width(), s.Height())
+	}
+	// The lines above the cursor's new row scroll off the top
+	if got, want := s.String(), "3\n4"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := s.Transcript(), "1\n2\n3\n4"; got != want {
+		t.Errorf("Transcript() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// Settings holds user preferences that are not tied to a single command
+type Settings struct {
+	PinnedSlots   map[int]string    `json:"pinned_slots,omitempty"`   // Quick-launch slot (1-9) → command ID
+	CategoryOrder []string          `json:"category_order,omitempty"` // Categories listed first, in this order
+	CategoryIcons map[string]string `json:"category_icons,omitempty"` // Category → icon shown in the categories bar
+
+	ConfirmBeforeRun   bool `json:"confirm_before_run,omitempty"`    // Ask before executing a command with enter
+	WrapNavigation     bool `json:"wrap_navigation,omitempty"`       // Wrap around at the ends of the command list
+	LowercaseTags      bool `json:"lowercase_tags,omitempty"`        // Lowercase tags when a command is saved
+	CopyIncludeHeader  bool `json:"copy_include_header,omitempty"`   // Include the Command/Started/Exit Code header when copying output
+	SaveResults        bool `json:"save_results,omitempty"`          // Keep the last result of each command so it can be reopened with o
+	BatchStopOnFailure bool `json:"batch_stop_on_failure,omitempty"` // Stop a batch run at the first command that fails
+
+	DisableNotifications bool `json:"disable_notifications,omitempty"` // Don't show a desktop notification when a background run finishes
+
+	PlaceholderHistory map[string][]string `json:"placeholder_history,omitempty"` // Placeholder name → recent values, newest first
+
+	LogRules []LogRule `json:"log_rules,omitempty"` // Extra output coloring rules, checked before the built-in ones
+
+	RedactPatterns []string `json:"redact_patterns,omitempty"` // Regular expressions masked in the output of every command
+
+	OutputMaxLines int  `json:"output_max_lines,omitempty"` // Lines of a run's output kept in the execution view (default DefaultOutputMaxLines)
+	InteractivePTY bool `json:"interactive_pty,omitempty"`  // Run interactive commands in a pseudo-terminal inside the execution view instead of handing over the terminal
+	OpenInTerminal bool `json:"open_in_terminal,omitempty"` // Open interactive commands in a new terminal window, leaving go-recipe running
+
+	ShellFlags   map[string][]string `json:"shell_flags,omitempty"`   // Shell name → flags placed before the command string
+	WindowsShell string              `json:"windows_shell,omitempty"` // Shell used on Windows: cmd (default), powershell or pwsh
+
+	ProjectRoot string `json:"project_root,omitempty"` // Directory relative working directories are resolved against (default: the current directory)
+}
+
+// LogRule colors output lines matching a regular expression
+type LogRule struct {
+	Pattern string `json:"pattern"` // Regular expression matched against each output line
+	Color   string `json:"color"`   // Foreground color, e.g. "#FF5555" or an ANSI color number
+}
+
+// SessionState is the part of the list view restored on the next launch
+type SessionState struct {
+	ActiveCategory  string `json:"active_category,omitempty"`   // Selected category
+	FilterText      string `json:"filter_text,omitempty"`       // Applied filter
+	SortMode        string `json:"sort_mode,omitempty"`         // Sort order by name, e.g. "recent"
+	GroupByCategory bool   `json:"group_by_category,omitempty"` // Whether the list is grouped by category
+	DetailedList    bool   `json:"detailed_list,omitempty"`     // Whether every row shows its description
+
+	Runs map[string]RunStats `json:"runs,omitempty"` // Run stats by command ID, recorded as runs happen
+}
+
+// RunStats are a command's run metadata. They live in the state file so a
+// run doesn't rewrite the commands file.
+type RunStats struct {
+	LastRun  time.Time `json:"last_run,omitzero"`   // When the command was last executed
+	RunCount int       `json:"run_count,omitempty"` // How many times it has been run
+	LastExit *int      `json:"last_exit,omitempty"` // Exit code of the last finished run
+}
+
+// DefaultSettings returns the settings used when no settings file exists
+func DefaultSettings() Settings {
+	return Settings{
+		PinnedSlots: map[int]string{},
+	}
+}
+
+// SlotFor returns the quick-launch slot the command is pinned to, or 0 if none
+func (s Settings) SlotFor(commandID string) int {
+	for slot, id := range s.PinnedSlots {
+		if id == commandID {
+			return slot
+		}
+	}
+	return 0
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Theme holds the colors the interface is drawn with. Colors are hex values
+// such as "#7D56F4" or ANSI color numbers such as "5".
+type Theme struct {
+	Name string `json:"name"` // Built-in theme the colors below start from: dark (default) or light
+
+	Title              string `json:"title"`               // Title bar text
+	TitleBackground    string `json:"title_background"`    // Title bar
+	Subtitle           string `json:"subtitle"`            // Subtitle text (the command line in the execution view)
+	SubtitleBackground string `json:"subtitle_background"` // Subtitle bar
+	Selected           string `json:"selected"`            // Selected row and active category text
+	SelectedBackground string `json:"selected_background"` // Selected row and active category
+	Item               string `json:"item"`                // List rows
+	Command            string `json:"command"`             // Command lines and form values
+	Description        string `json:"description"`         // Descriptions
+	Accent             string `json:"accent"`              // Categories, form labels and the scroll bar
+	Text               string `json:"text"`                // Prompt text
+	Error              string `json:"error"`               // Errors and warnings
+	Info               string `json:"info"`                // Status messages, e.g. "Output saved to ..."
+	Output             string `json:"output"`              // Command output text
+	OutputBackground   string `json:"output_background"`   // Command output box
+	Help               string `json:"help"`                // Key hints
+	Muted              string `json:"muted"`               // Placeholder text for empty form fields
+	Highlight          string `json:"highlight"`           // Lines selected in the output
+	Match              string `json:"match"`               // Characters matched by the filter
+	Cursor             string `json:"cursor"`              // Text input cursor
+	Editing            string `json:"editing"`             // Form field being edited
+	Confirm            string `json:"confirm"`             // Border of the run confirmation prompt
+	Success            string `json:"success"`             // Exit code of a successful run
+	Failure            string `json:"failure"`             // Exit code of a failed run
+	ErrorBlock         string `json:"error_block"`         // Background of the error lines in a result
+	Stderr             string `json:"stderr"`              // Stderr section of a result when shown separately
+}
+
+// DarkTheme returns the default theme, made for dark terminals
+func DarkTheme() Theme {
+	return Theme{
+		Name:               "dark",
+		Title:              "#FAFAFA",
+		TitleBackground:    "#7D56F4",
+		Subtitle:           "#FAFAFA",
+		SubtitleBackground: "#383838",
+		Selected:           "#FFFFFF",
+		SelectedBackground: "#7D56F4",
+		Item:               "#DDDDDD",
+		Command:            "#36A9E0",
+		Description:        "#4CAF50",
+		Accent:             "#7D56F4",
+		Text:               "#FFFFFF",
+		Error:              "#FF0000",
+		Info:               "#61AFEF",
+		Output:             "#00FF00",
+		OutputBackground:   "#222222",
+		Help:               "#BBBBBB",
+		Muted:              "#666666",
+		Highlight:          "#5A3FC0",
+		Match:              "#FFCC00",
+		Cursor:             "#FF00FF",
+		Editing:            "#008800",
+		Confirm:            "#FF8800",
+		Success:            "#04B575",
+		Failure:            "#FF5555",
+		ErrorBlock:         "#8B0000",
+		Stderr:             "#FFA657",
+	}
+}
+
+// LightTheme returns a theme that stays readable on light terminals
+func LightTheme() Theme {
+	return Theme{
+		Name:               "light",
+		Title:              "#FFFFFF",
+		TitleBackground:    "#5A3FC0",
+		Subtitle:           "#FFFFFF",
+		SubtitleBackground: "#555555",
+		Selected:           "#FFFFFF",
+		SelectedBackground: "#5A3FC0",
+		Item:               "#333333",
+		Command:            "#005F87",
+		Description:        "#2E7D32",
+		Accent:             "#5A3FC0",
+		Text:               "#1B1B1B",
+		Error:              "#C00000",
+		Info:               "#00579B",
+		Output:             "#1B1B1B",
+		OutputBackground:   "#F0F0F0",
+		Help:               "#666666",
+		Muted:              "#999999",
+		Highlight:          "#9C8AE6",
+		Match:              "#B35900",
+		Cursor:             "#C000C0",
+		Editing:            "#2E7D32",
+		Confirm:            "#D35400",
+		Success:            "#2E7D32",
+		Failure:            "#C62828",
+		ErrorBlock:         "#B71C1C",
+		Stderr:             "#B35900",
+	}
+}
+
+// builtinThemes maps theme names to their constructors
+var builtinThemes = map[string]func() Theme{
+	"dark":  DarkTheme,
+	"light": LightTheme,
+}
+
+// BuiltinTheme returns the built-in theme with the given name; an empty name
+// selects the dark theme
+func BuiltinTheme(name string) (Theme, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return DarkTheme(), nil
+	}
+	theme, ok := builtinThemes[name]
+	if !ok {
+		return DarkTheme(), fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	return theme(), nil
+}
+
+// ThemeNames returns the names of the built-in themes in sorted order
+func ThemeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
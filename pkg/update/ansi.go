@@ -0,0 +1,138 @@
+package update
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creack/pty"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// Color modes for model.Command.ColorMode
+const (
+	ColorAuto   = "auto"   // keep whatever colors the command emits (the default)
+	ColorAlways = "always" // run in a pseudo-terminal so tools that check for one emit colors
+	ColorNever  = "never"  // ask for plain output with NO_COLOR and strip escape codes
+)
+
+// ValidateColorMode checks that mode is empty or one of the color modes
+func ValidateColorMode(mode string) error {
+	switch mode {
+	case "", ColorAuto, ColorAlways, ColorNever:
+		return nil
+	}
+	return errors.New("ColorMode must be auto, always or never")
+}
+
+// ansiPattern matches terminal escape sequences: CSI (colors, cursor
+// movement), OSC (titles, links) and two-byte escapes
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// StripANSI removes all terminal escape sequences from s
+func StripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// displayableOutput prepares raw output for the execution view: color (SGR)
+// sequences are kept, other escape sequences are dropped, lines redrawn
+// with carriage returns (progress bars, PTY line endings) keep only their
+// last state and backspaces echoed by a terminal erase what they went over
+func displayableOutput(s string) string {
+	s = ansiPattern.ReplaceAllStringFunc(s, func(seq string) string {
+		if strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m") {
+			return seq
+		}
+		return ""
+	})
+	if !strings.ContainsAny(s, "\r\b") {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if idx := strings.LastIndex(line, "\r"); idx >= 0 {
+			line = line[idx+1:]
+		}
+		lines[i] = applyBackspaces(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// applyBackspaces removes each backspace along with the character before it
+func applyBackspaces(line string) string {
+	if !strings.Contains(line, "\b") {
+		return line
+	}
+	var kept []rune
+	for _, r := range line {
+		if r != '\b' {
+			kept = append(kept, r)
+		} else if len(kept) > 0 {
+			kept = kept[:len(kept)-1]
+		}
+	}
+	return string(kept)
+}
+
+// lockedWriter serializes the writes of the goroutines copying a command's
+// stdout and stderr when they don't share one writer
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// runWithOutput runs cmd with stdout and stderr written to stream, through a
+// pseudo-terminal when the command asks for colors. Stderr is also copied to
+// stderr when it is set; a pseudo-terminal merges the two, so nothing is
+// copied then.
+func runWithOutput(cmd *exec.Cmd, command model.Command, stream, stderr io.Writer) error {
+	if command.ColorMode == ColorNever {
+		w := &redactWriter{w: stream, redact: StripANSI}
+		defer w.Flush()
+		stream = w
+	}
+	if command.ColorMode == ColorAlways {
+		ptmx, err := pty.Start(cmd)
+		if err == nil {
+			copied := make(chan struct{})
+			go func() {
+				_, _ = io.Copy(stream, ptmx)
+				close(copied)
+			}()
+			err = cmd.Wait()
+			// Drain the terminal, unless a leftover child process keeps it open
+			select {
+			case <-copied:
+			case <-time.After(time.Second):
+			}
+			ptmx.Close()
+			<-copied
+			return err
+		}
+		if !errors.Is(err, pty.ErrUnsupported) {
+			return err
+		}
+		// No pseudo-terminals on this platform: fall back to pipes
+	}
+	if stderr == nil {
+		cmd.Stdout = stream
+		cmd.Stderr = stream
+		return cmd.Run()
+	}
+	locked := &lockedWriter{w: stream}
+	cmd.Stdout = locked
+	cmd.Stderr = io.MultiWriter(locked, stderr)
+	return cmd.Run()
+}
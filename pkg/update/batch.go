@@ -0,0 +1,104 @@
+package update
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// batchSeparator is placed between the outputs of the commands of a batch
+var batchSeparator = "\n\n" + strings.Repeat("═", 60) + "\n\n"
+
+// markedCommands returns the marked commands in config file order
+func markedCommands(m model.Model) []model.Command {
+	var commands []model.Command
+	for _, cmd := range m.AllCommands {
+		if m.Marked[cmd.ID] {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
+}
+
+// startBatch runs the marked commands one after another in the execution view,
+// or all at once in background mode. When any of them asks before running,
+// the whole batch is confirmed first (see runBatch).
+func startBatch(m model.Model) (model.Model, tea.Cmd) {
+	commands := markedCommands(m)
+	if len(commands) == 0 {
+		m.Error = "No commands marked. Press space to mark commands for a batch run"
+		return m, nil
+	}
+	for _, cmd := range commands {
+		if cmd.Interactive {
+			m.Error = fmt.Sprintf("%q is interactive and can't run in a batch", cmd.Name)
+			return m, nil
+		}
+		if len(parsePlaceholders(cmd.Command)) > 0 {
+			m.Error = fmt.Sprintf("%q has placeholders and can't run in a batch", cmd.Name)
+			return m, nil
+		}
+	}
+
+	for _, cmd := range commands {
+		if cmd.AsksBeforeRun(m.Settings) {
+			m.CurrentMode = model.ModeConfirm
+			m.ConfirmBatch = true
+			return m, nil
+		}
+	}
+	return runBatch(commands, m)
+}
+
+// runBatch starts the batch of commands once it no longer needs confirming
+func runBatch(commands []model.Command, m model.Model) (model.Model, tea.Cmd) {
+	// In background mode the marked commands all start at once
+	if m.RunInBackground {
+		var cmds []tea.Cmd
+		for _, command := range commands {
+			recordRun(&m, command.ID)
+			var cmd tea.Cmd
+			if m, cmd = startBackground(command, m); cmd == nil {
+				break
+			}
+			cmds = append(cmds, cmd)
+		}
+		if m.Error == "" {
+			m.Info = fmt.Sprintf("Started %d background tasks. Press T to follow them", len(cmds))
+		}
+		return m, tea.Batch(cmds...)
+	}
+
+	m, cmd := executeCommand(commands[0], m)
+	if m.Executing {
+		m.BatchQueue = commands[1:]
+		m.BatchIndex = 1
+		m.BatchTotal = len(commands)
+	}
+	return m, cmd
+}
+
+// nextInBatch starts the next command of the batch after result, unless the
+// run was cancelled or failed with batch_stop_on_failure set
+func nextInBatch(result Result, m model.Model) (model.Model, tea.Cmd) {
+	failed := result.Error != nil || result.ExitCode != 0
+	if result.ExitCode == ExitCodeCancelled || (failed && m.Settings.BatchStopOnFailure) {
+		m.ExecutionOutput += fmt.Sprintf("\n\nBatch stopped: %d of %d commands not run", len(m.BatchQueue), m.BatchTotal)
+		m.BatchQueue = nil
+		return m, nil
+	}
+
+	next := m.BatchQueue[0]
+	m.BatchQueue = m.BatchQueue[1:]
+	m.BatchIndex++
+	m.BatchOutput = m.ExecutionOutput + batchSeparator
+	m.ExecutionOutput = m.BatchOutput
+	m.ExecutingCommand = &next
+	recordRun(&m, next.ID)
+
+	// The poll and spinner ticks of the batch are still running
+	return startForeground(next, m)
+}
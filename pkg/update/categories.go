@@ -0,0 +1,146 @@
+package update
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// managedCategories returns the categories listed in the category manager:
+// every category in use, without "All" and "Favorites"
+func managedCategories(m model.Model) []string {
+	var categories []string
+	for _, category := range m.Categories {
+		if !model.PseudoCategory(category) {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// handleCategoriesKeyPress navigates the category manager and renames or
+// deletes the selected category
+func handleCategoriesKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	categories := managedCategories(m)
+	deleteCategory := m.DeleteCategory
+	m.DeleteCategory = ""
+
+	switch msg.String() {
+	case "esc", "C":
+		m.ShowCategories = false
+	case "up", "k":
+		if m.CategoryIndex > 0 {
+			m.CategoryIndex--
+		}
+	case "down", "j":
+		if m.CategoryIndex < len(categories)-1 {
+			m.CategoryIndex++
+		}
+	case "r", "enter":
+		if m.CategoryIndex < len(categories) {
+			m.RenamingCategory = true
+			m.InputBuffer = categories[m.CategoryIndex]
+		}
+	case "d":
+		if m.CategoryIndex >= len(categories) {
+			break
+		}
+		category := categories[m.CategoryIndex]
+		// Deleting touches every command in the category, so ask for a second d
+		if deleteCategory != category {
+			m.DeleteCategory = category
+			m.Info = fmt.Sprintf("Press d again to remove the category %q from its commands", category)
+			break
+		}
+		return renameCategory(m, category, "")
+	}
+	return m, nil
+}
+
+// handleCategoryRename edits the new name of the selected category
+func handleCategoryRename(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.RenamingCategory = false
+		m.InputBuffer = ""
+	case "enter":
+		categories := managedCategories(m)
+		newName := strings.TrimSpace(m.InputBuffer)
+		m.RenamingCategory = false
+		m.InputBuffer = ""
+		if newName == "" {
+			m.Error = "Category name can't be empty (use d to delete a category)"
+			return m, nil
+		}
+		if m.CategoryIndex < len(categories) {
+			return renameCategory(m, categories[m.CategoryIndex], newName)
+		}
+	case "backspace":
+		m.InputBuffer = trimLastRune(m.InputBuffer)
+	case "ctrl+u":
+		m.InputBuffer = ""
+	default:
+		m.InputBuffer += typedText(msg, false)
+	}
+	return m, nil
+}
+
+// renameCategory moves every command in oldName to newName (merging when it
+// exists; an empty newName deletes the category), carries its position and
+// icon in the settings over, and saves both files
+func renameCategory(m model.Model, oldName, newName string) (model.Model, tea.Cmd) {
+	changed := config.RenameCategory(m.AllCommands, oldName, newName)
+	if changed == 0 {
+		return m, nil
+	}
+
+	settingsChanged := false
+	var order []string
+	for _, category := range m.Settings.CategoryOrder {
+		if category == oldName {
+			category = newName
+			settingsChanged = true
+		}
+		if category != "" && !slices.Contains(order, category) {
+			order = append(order, category)
+		}
+	}
+	m.Settings.CategoryOrder = order
+	if icon, ok := m.Settings.CategoryIcons[oldName]; ok {
+		delete(m.Settings.CategoryIcons, oldName)
+		if _, exists := m.Settings.CategoryIcons[newName]; newName != "" && !exists {
+			m.Settings.CategoryIcons[newName] = icon
+		}
+		settingsChanged = true
+	}
+
+	if m.ActiveCategory == oldName {
+		m.ActiveCategory = newName
+		if newName == "" {
+			m.ActiveCategory = "All"
+		}
+	}
+	m.Categories = config.GetCategories(m.AllCommands, m.Settings.CategoryOrder)
+	refreshVisible(&m)
+	m.CategoryIndex = max(min(m.CategoryIndex, len(managedCategories(m))-1), 0)
+
+	saveCommands(&m)
+	if m.UnsavedChanges {
+		return m, nil
+	}
+	if settingsChanged {
+		if err := config.SaveSettings(m.Settings); err != nil {
+			m.Error = fmt.Sprintf("Failed to save settings: %v", err)
+			return m, nil
+		}
+	}
+	if newName == "" {
+		return flash(m, fmt.Sprintf("Removed category %q from %d command(s)", oldName, changed))
+	}
+	return flash(m, fmt.Sprintf("Moved %d command(s) from %q to %q", changed, oldName, newName))
+}
@@ -0,0 +1,15 @@
+package update
+
+import (
+	"errors"
+
+	"github.com/atotto/clipboard"
+)
+
+// copyToClipboard writes text to the system clipboard
+func copyToClipboard(text string) error {
+	if clipboard.Unsupported {
+		return errors.New("no clipboard available (install xclip, xsel or wl-clipboard)")
+	}
+	return clipboard.WriteAll(text)
+}
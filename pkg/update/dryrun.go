@@ -0,0 +1,126 @@
+package update
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// DescribeRun resolves a command the way a real run does (shell, working
+// directory, environment and stdin) without starting it, and describes what
+// would run. Placeholders are listed as they are; fill them in first to see
+// the final command line.
+func DescribeRun(command model.Command) (string, error) {
+	// A dry run must not create the working directory, so one that would be
+	// created is only reported
+	viaShell := command.UseShell || command.Interactive
+	setup, err := resolveRun(command, viaShell)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(command.StdinFile) != "" {
+		if _, err := loadStdinFile(command.StdinFile); err != nil {
+			return "", err
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Command: %s\n", command.Command)
+	if names := parsePlaceholders(command.Command); len(names) > 0 {
+		fmt.Fprintf(&sb, "Placeholders: %s (filled in when run from the TUI)\n", strings.Join(names, ", "))
+	}
+	if viaShell {
+		fmt.Fprintf(&sb, "Shell: %s\n", strings.Join(setup.argv[:len(setup.argv)-1], " "))
+	} else {
+		path, err := exec.LookPath(setup.argv[0])
+		if err != nil {
+			path = setup.argv[0]
+		}
+		fmt.Fprintf(&sb, "Shell: none, %s runs directly\n", path)
+	}
+	quoted := make([]string, len(setup.argv))
+	for i, arg := range setup.argv {
+		quoted[i] = shellQuote(arg)
+	}
+	fmt.Fprintf(&sb, "Argv: %s\n", strings.Join(quoted, " "))
+	if pre := strings.TrimSpace(command.PreCommand); pre != "" && !command.Interactive {
+		fmt.Fprintf(&sb, "Pre-command: %s\n", pre)
+	}
+	if post := strings.TrimSpace(command.PostCommand); post != "" && !command.Interactive {
+		fmt.Fprintf(&sb, "Post-command: %s\n", post)
+	}
+
+	switch {
+	case setup.createdDir != "":
+		fmt.Fprintf(&sb, "Directory: %s (will be created)\n", setup.createdDir)
+	case setup.dir != "":
+		fmt.Fprintf(&sb, "Directory: %s\n", setup.dir)
+	default:
+		wd, _ := os.Getwd()
+		fmt.Fprintf(&sb, "Directory: %s (current directory)\n", wd)
+	}
+
+	if len(setup.env) > 0 {
+		sb.WriteString("Environment (added):\n")
+		for _, entry := range setup.env {
+			fmt.Fprintf(&sb, "  %s\n", entry)
+		}
+	}
+	if strings.TrimSpace(command.StdinFile) != "" {
+		path, _ := expandDirPlaceholders(command.StdinFile)
+		fmt.Fprintf(&sb, "Stdin: %s\n", path)
+	}
+	if command.Interactive {
+		sb.WriteString("Interactive: runs attached to the terminal\n")
+	}
+	if command.TimeoutSeconds > 0 {
+		fmt.Fprintf(&sb, "Timeout: %ds\n", command.TimeoutSeconds)
+	}
+	if command.Retries > 0 {
+		fmt.Fprintf(&sb, "Retries: %d (%ds apart)\n", command.Retries, command.RetryDelaySeconds)
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
+
+// shellQuote quotes s for a POSIX shell when it contains anything but plain
+// word characters
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:,+@%", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// showDryRun shows in the execution view what running the command would do
+func showDryRun(command model.Command, m model.Model) (model.Model, tea.Cmd) {
+	description, err := DescribeRun(command)
+	if err != nil {
+		m.Error = fmt.Sprintf("Dry run of %q failed: %v", command.Name, err)
+		return m, nil
+	}
+
+	m.Executing = true
+	m.ExecutingCommand = &command
+	m.ViewingDryRun = true
+	m.ViewingLastResult = false
+	m.AlternateOutput = ""
+	m.ExecutionOutput = RedactOutput(description, command, m.Settings)
+	m.StreamedOutput = boundedOutput(m.ExecutionOutput, m.Settings)
+	m.OutputScrollPosition = 0
+	m.FollowOutput = false
+	m.SelectionActive = false
+	stopWatching(&m)
+	m.Spinning = false
+	m.ExecutionStart = time.Time{}
+	m.BatchTotal = 0
+	m.BatchOutput = ""
+	return m, nil
+}
@@ -0,0 +1,137 @@
+package update
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// editConfig suspends the TUI and opens the commands file in the user's
+// editor; the file is reloaded when the editor exits (see handleConfigEdited)
+func editConfig(m model.Model) (model.Model, tea.Cmd) {
+	// Reloading would drop changes that only exist in memory
+	if m.UnsavedChanges {
+		m.Error = "Some changes aren't saved yet. Press ctrl+s to save them before editing the file"
+		return m, nil
+	}
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		m.Error = err.Error()
+		return m, nil
+	}
+	cmd, err := editorCommand(configPath)
+	if err != nil {
+		m.Error = err.Error()
+		return m, nil
+	}
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return ConfigEditedMsg{Error: err}
+	})
+}
+
+// editorCommand builds the command that opens path in $EDITOR, falling back
+// to vi (notepad on Windows)
+func editorCommand(path string) (*exec.Cmd, error) {
+	if parts := strings.Fields(os.Getenv("EDITOR")); len(parts) > 0 {
+		return exec.Command(parts[0], append(parts[1:], path)...), nil
+	}
+	editor := "vi"
+	if runtime.GOOS == "windows" {
+		editor = "notepad"
+	}
+	p, err := exec.LookPath(editor)
+	if err != nil {
+		return nil, fmt.Errorf("no editor found: set $EDITOR")
+	}
+	return exec.Command(p, path), nil
+}
+
+// handleConfigEdited reloads the commands file after it was edited by hand.
+// A file that doesn't parse or has invalid commands is copied to a backup,
+// since the next save writes the commands loaded before over it, and a
+// warning stays up until dismissed.
+func handleConfigEdited(msg ConfigEditedMsg, m model.Model) (model.Model, tea.Cmd) {
+	if msg.Error != nil {
+		m.Error = fmt.Sprintf("Editor exited with error: %v", msg.Error)
+		return m, nil
+	}
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		m.Error = err.Error()
+		return m, nil
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		m.Error = fmt.Sprintf("Failed to read %s: %v", configPath, err)
+		return m, nil
+	}
+	commands, err := config.DecodeCommands(configPath, data)
+	if err == nil {
+		err = ValidateCommands(commands)
+	}
+	if err != nil {
+		backupPath, backupErr := config.BackupConfig(configPath)
+		if backupErr != nil {
+			m.Warning = fmt.Sprintf("%s is not valid, keeping the commands loaded before: %v. It couldn't be backed up (%v), so fix it before anything is saved", configPath, err, backupErr)
+			return m, nil
+		}
+		m.Warning = fmt.Sprintf("%s is not valid, keeping the commands loaded before: %v. Your edit was copied to %s since the next save overwrites the file", configPath, err, backupPath)
+		return m, nil
+	}
+
+	// Commands added by hand get an ID like those added in the form
+	assigned := false
+	for i := range commands {
+		if commands[i].ID == "" {
+			commands[i].ID = config.NewCommandID(commands)
+			assigned = true
+		}
+	}
+
+	m.AllCommands = commands
+	m.DeletedCommand = nil
+	m.Warning = ""
+	m.Categories = config.GetCategories(m.AllCommands, m.Settings.CategoryOrder)
+	if !slices.Contains(m.Categories, m.ActiveCategory) {
+		m.ActiveCategory = "All"
+	}
+	refreshVisible(&m)
+	if assigned {
+		saveCommands(&m)
+		if m.UnsavedChanges {
+			return m, nil
+		}
+	}
+	return flash(m, fmt.Sprintf("Reloaded %d command(s) from %s", len(commands), configPath))
+}
+
+// ValidateCommands checks what the form would have enforced on commands
+// edited or imported as a whole: every command has a name and a command line,
+// IDs are unique and no alias is used by two commands
+func ValidateCommands(commands []model.Command) error {
+	ids := map[string]bool{}
+	for i, cmd := range commands {
+		if strings.TrimSpace(cmd.Name) == "" || strings.TrimSpace(cmd.Command) == "" {
+			return fmt.Errorf("command %d needs both a name and a command", i+1)
+		}
+		if alias, other := aliasTaken(commands[:i], cmd); other != nil {
+			return fmt.Errorf("alias %q is used by both %q and %q", alias, other.Name, cmd.Name)
+		}
+		if cmd.ID == "" {
+			continue
+		}
+		if ids[cmd.ID] {
+			return fmt.Errorf("ID %s is used by more than one command", cmd.ID)
+		}
+		ids[cmd.ID] = true
+	}
+	return nil
+}
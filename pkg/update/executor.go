@@ -2,12 +2,15 @@ package update
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
@@ -15,22 +18,235 @@ import (
 	"github.com/creack/pty"
 )
 
+// ShellFlags maps a shell's base name to the flags placed before the command
+// string when running via the shell. Use SetShellFlags to apply user overrides.
+var ShellFlags = map[string][]string{
+	"bash":       {"-lc"},
+	"zsh":        {"-lc"},
+	"fish":       {"-c"},
+	"sh":         {"-c"},
+	"cmd":        {"/S", "/C"},
+	"powershell": {"-NoProfile", "-Command"},
+	"pwsh":       {"-NoProfile", "-Command"},
+}
+
+// windowsShell is the shell used on Windows: cmd, powershell or pwsh
+var windowsShell = "cmd"
+
+// SetWindowsShell selects the shell used on Windows; empty keeps cmd
+func SetWindowsShell(name string) {
+	if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+		windowsShell = name
+	}
+}
+
+// projectRoot is the directory relative working directories are resolved
+// against; empty uses the current directory
+var projectRoot string
+
+// SetProjectRoot sets the directory relative working directories are resolved
+// against; it supports ~, $HOME and ${cwd}, and empty means the current directory
+func SetProjectRoot(dir string) {
+	projectRoot = strings.TrimSpace(dir)
+}
+
+// userShell returns the shell commands run through: $SHELL (or bash) on
+// Unix, and the configured Windows shell (cmd via %ComSpec%) on Windows
+func userShell() string {
+	if runtime.GOOS == "windows" {
+		if windowsShell == "cmd" {
+			if comspec := os.Getenv("ComSpec"); comspec != "" {
+				return comspec
+			}
+			return "cmd.exe"
+		}
+		return windowsShell
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "bash"
+}
+
+// commandShell returns the shell a command runs through: its own Shell when
+// set, otherwise the user's shell
+func commandShell(command model.Command) string {
+	if command.Shell != "" {
+		return command.Shell
+	}
+	return userShell()
+}
+
+// shellName returns the lowercase base name of a shell without .exe
+func shellName(shell string) string {
+	return strings.TrimSuffix(strings.ToLower(filepath.Base(shell)), ".exe")
+}
+
+// defaultShellFlags are used for shells missing from ShellFlags
+var defaultShellFlags = []string{"-lc"}
+
+// SetShellFlags merges user-configured shell flags over the built-in defaults
+func SetShellFlags(overrides map[string][]string) {
+	for shell, flags := range overrides {
+		ShellFlags[shell] = flags
+	}
+}
+
+// shellArgv composes the argv that runs script through shell, e.g.
+// ["/bin/bash", "-lc", script]
+func shellArgv(shell, script string) []string {
+	flags, ok := ShellFlags[shellName(shell)]
+	if !ok {
+		flags = defaultShellFlags
+	}
+	argv := append([]string{shell}, flags...)
+	return append(argv, script)
+}
+
+// Exit codes reported for commands that were killed before finishing
+const (
+	ExitCodeTimeout   = -2 // killed by the command's timeout
+	ExitCodeCancelled = -3 // killed on the user's request
+)
+
+// commandContext returns the context a command runs under, derived from
+// parent and bounded by its TimeoutSeconds when set
+func commandContext(parent context.Context, command model.Command) (context.Context, context.CancelFunc) {
+	if command.TimeoutSeconds > 0 {
+		return context.WithTimeout(parent, time.Duration(command.TimeoutSeconds)*time.Second)
+	}
+	return context.WithCancel(parent)
+}
+
+// checkContext replaces the error and exit code of a command that was killed
+// because its context timed out or was cancelled
+func checkContext(ctx context.Context, command model.Command, exitCode int, err error) (int, error) {
+	if err == nil {
+		return exitCode, err
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ExitCodeTimeout, fmt.Errorf("command timed out after %ds and was killed", command.TimeoutSeconds)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return ExitCodeCancelled, fmt.Errorf("command cancelled")
+	}
+	return exitCode, err
+}
+
 // Result represents the outcome of an executed command
 type Result struct {
 	Command   model.Command
-	Output    string
+	Output    string // Stdout and stderr combined
+	Stderr    string // Stderr on its own (empty when run in a pseudo-terminal)
 	Error     error
 	StartTime time.Time
 	EndTime   time.Time
 	ExitCode  int
+	Attempts  int // Number of runs, more than 1 when failures were retried
+}
+
+// withRetries calls run until it succeeds or the command's retries are used
+// up, waiting RetryDelaySeconds in between. Each retry is announced on out.
+// Cancelling ctx stops retrying.
+func withRetries(ctx context.Context, command model.Command, out io.Writer, run func() Result) Result {
+	startTime := time.Now()
+	total := max(command.Retries, 0) + 1
+	for attempt := 1; ; attempt++ {
+		result := run()
+		result.StartTime = startTime
+		result.Attempts = attempt
+		if result.ExitCode == 0 || result.ExitCode == ExitCodeCancelled || attempt >= total {
+			return result
+		}
+
+		delay := time.Duration(command.RetryDelaySeconds) * time.Second
+		fmt.Fprintf(out, "\n--- Attempt %d/%d failed with exit code %d, retrying", attempt, total, result.ExitCode)
+		if delay > 0 {
+			fmt.Fprintf(out, " in %s", delay)
+		}
+		fmt.Fprint(out, " ---\n\n")
+		select {
+		case <-ctx.Done():
+			result.ExitCode = ExitCodeCancelled
+			result.Error = fmt.Errorf("command cancelled")
+			result.EndTime = time.Now()
+			return result
+		case <-time.After(delay):
+		}
+	}
+}
+
+// withHooks runs the command's PreCommand before run and its PostCommand
+// after it, writing their output to out between separator lines. A failing
+// pre-command skips run and its result is returned instead; the post-command
+// runs whatever run returned (unless ctx was cancelled) and a failure of it
+// is only reported in the output. Interactive commands run without hooks.
+func withHooks(ctx context.Context, command model.Command, out io.Writer, run func() Result) Result {
+	pre, post := strings.TrimSpace(command.PreCommand), strings.TrimSpace(command.PostCommand)
+	if command.Interactive || (pre == "" && post == "") {
+		return run()
+	}
+
+	startTime := time.Now()
+	if pre != "" {
+		fmt.Fprintf(out, "--- Pre-command: %s ---\n", pre)
+		hook := runHook(ctx, command, pre, out)
+		if hook.ExitCode != 0 {
+			fmt.Fprintf(out, "\n--- Pre-command failed with exit code %d, command skipped ---\n", hook.ExitCode)
+			hook.Command = command
+			hook.Error = fmt.Errorf("pre-command failed: %w", hook.Error)
+			return hook
+		}
+		fmt.Fprintf(out, "\n--- Command: %s ---\n", command.Command)
+	}
+
+	result := run()
+	result.StartTime = startTime
+	if post != "" && ctx.Err() == nil {
+		fmt.Fprintf(out, "\n--- Post-command: %s ---\n", post)
+		if hook := runHook(ctx, command, post, out); hook.ExitCode != 0 {
+			fmt.Fprintf(out, "\n--- Post-command failed with exit code %d ---\n", hook.ExitCode)
+		}
+		result.EndTime = time.Now()
+	}
+	return result
+}
+
+// runHook runs a pre- or post-command script once with the command's shell,
+// working directory, environment and timeout, but without its stdin file
+func runHook(ctx context.Context, command model.Command, script string, out io.Writer) Result {
+	hook := command
+	hook.Command = script
+	hook.StdinFile = ""
+	return executeStreamingOnce(ctx, hook, out)
 }
 
-// ExecuteCommand runs a shell command and returns the result
+// ExecuteCommand runs a shell command and returns the result. Failed runs are
+// retried according to the command's Retries, keeping the output of each, and
+// the command's pre- and post-commands run around them.
 func ExecuteCommand(command model.Command) Result {
+	return ExecuteCommandContext(context.Background(), command)
+}
+
+// ExecuteCommandContext is like ExecuteCommand but kills the command when ctx
+// is cancelled.
+func ExecuteCommandContext(parent context.Context, command model.Command) Result {
+	var output strings.Builder
+	result := withHooks(parent, command, &output, func() Result {
+		return withRetries(parent, command, &output, func() Result {
+			r := executeOnce(parent, command)
+			output.WriteString(r.Output)
+			return r
+		})
+	})
+	result.Output = output.String()
+	return result
+}
+
+// executeOnce runs a shell command once and returns the result
+func executeOnce(parent context.Context, command model.Command) Result {
 	startTime := time.Now()
 
-	// If UseShell, run via shell to preserve pipes/quotes; else split fields
-	var cmd *exec.Cmd
 	if strings.TrimSpace(command.Command) == "" {
 		return Result{
 			Command:   command,
@@ -41,37 +257,17 @@ func ExecuteCommand(command model.Command) Result {
 			ExitCode:  -1,
 		}
 	}
-	if command.UseShell {
-		// Unix shells; Windows support can be extended later when needed
-		shell := os.Getenv("SHELL")
-		if shell == "" {
-			shell = "bash"
-		}
-		cmd = exec.Command(shell, "-lc", command.Command)
-	} else {
-		// Split the command string into parts
-		cmdParts := strings.Fields(command.Command)
-		if len(cmdParts) == 0 {
-			return Result{
-				Command:   command,
-				Output:    "",
-				Error:     fmt.Errorf("empty command"),
-				StartTime: startTime,
-				EndTime:   time.Now(),
-				ExitCode:  -1,
-			}
-		}
-		cmd = exec.Command(cmdParts[0], cmdParts[1:]...)
-	}
 
-	// Resolve working directory according to command settings
-	if dir, derr := resolveWorkingDir(command); derr == nil && dir != "" {
-		cmd.Dir = dir
-	} else if derr != nil {
+	ctx, cancel := commandContext(parent, command)
+	defer cancel()
+
+	// If UseShell, run via shell to preserve pipes/quotes; else split fields
+	cmd, err := newExecCmd(ctx, command, command.UseShell)
+	if err != nil {
 		return Result{
 			Command:   command,
 			Output:    "",
-			Error:     derr,
+			Error:     err,
 			StartTime: startTime,
 			EndTime:   time.Now(),
 			ExitCode:  -1,
@@ -84,7 +280,7 @@ func ExecuteCommand(command model.Command) Result {
 	cmd.Stderr = &stderr
 
 	// Run the command
-	err := cmd.Run()
+	err = cmd.Run()
 
 	// Calculate exit code
 	exitCode := 0
@@ -95,6 +291,7 @@ func ExecuteCommand(command model.Command) Result {
 			exitCode = -1
 		}
 	}
+	exitCode, err = checkContext(ctx, command, exitCode, err)
 
 	// Combine stdout and stderr
 	output := stdout.String()
@@ -104,11 +301,17 @@ func ExecuteCommand(command model.Command) Result {
 		}
 		output += stderr.String()
 	}
+	errOutput := stderr.String()
+	if command.ColorMode == ColorNever {
+		output = StripANSI(output)
+		errOutput = StripANSI(errOutput)
+	}
 
 	// Create result
 	result := Result{
 		Command:   command,
 		Output:    output,
+		Stderr:    errOutput,
 		Error:     err,
 		StartTime: startTime,
 		EndTime:   time.Now(),
@@ -120,39 +323,40 @@ func ExecuteCommand(command model.Command) Result {
 
 // ExecuteCommandStreaming runs a command and streams output to the provided writer.
 func ExecuteCommandStreaming(command model.Command, stream io.Writer) Result {
+	return ExecuteCommandStreamingContext(context.Background(), command, stream)
+}
+
+// ExecuteCommandStreamingContext is like ExecuteCommandStreaming but kills the
+// command when ctx is cancelled. Failed runs are retried according to the
+// command's Retries, streaming each attempt, between the output of its pre-
+// and post-commands.
+func ExecuteCommandStreamingContext(parent context.Context, command model.Command, stream io.Writer) Result {
+	return withHooks(parent, command, stream, func() Result {
+		return withRetries(parent, command, stream, func() Result {
+			return executeStreamingOnce(parent, command, stream)
+		})
+	})
+}
+
+// executeStreamingOnce runs a command once, streaming its output to stream
+func executeStreamingOnce(parent context.Context, command model.Command, stream io.Writer) Result {
 	startTime := time.Now()
 
 	if strings.TrimSpace(command.Command) == "" {
 		return Result{Command: command, Error: fmt.Errorf("empty command"), StartTime: startTime, EndTime: time.Now(), ExitCode: -1}
 	}
 
-	var cmd *exec.Cmd
-	if command.UseShell || command.Interactive {
-		// For interactive commands, prefer running via shell to preserve environment
-		shell := os.Getenv("SHELL")
-		if shell == "" {
-			shell = "bash"
-		}
-		cmd = exec.Command(shell, "-lc", command.Command)
-	} else {
-		parts := strings.Fields(command.Command)
-		if len(parts) == 0 {
-			return Result{Command: command, Error: fmt.Errorf("empty command"), StartTime: startTime, EndTime: time.Now(), ExitCode: -1}
-		}
-		cmd = exec.Command(parts[0], parts[1:]...)
-	}
+	ctx, cancel := commandContext(parent, command)
+	defer cancel()
 
-	if dir, derr := resolveWorkingDir(command); derr == nil && dir != "" {
-		cmd.Dir = dir
-	} else if derr != nil {
-		return Result{Command: command, Error: derr, StartTime: startTime, EndTime: time.Now(), ExitCode: -1}
+	// For interactive commands, prefer running via shell to preserve environment
+	cmd, err := newExecCmd(ctx, command, command.UseShell || command.Interactive)
+	if err != nil {
+		return Result{Command: command, Error: err, StartTime: startTime, EndTime: time.Now(), ExitCode: -1}
 	}
 
-	// Attach streaming writer
-	cmd.Stdout = stream
-	cmd.Stderr = stream
-
-	err := cmd.Run()
+	var stderr bytes.Buffer
+	err = runWithOutput(cmd, command, stream, &stderr)
 
 	exitCode := 0
 	if err != nil {
@@ -162,8 +366,13 @@ func ExecuteCommandStreaming(command model.Command, stream io.Writer) Result {
 			exitCode = -1
 		}
 	}
+	exitCode, err = checkContext(ctx, command, exitCode, err)
 
-	return Result{Command: command, Output: "", Error: err, StartTime: startTime, EndTime: time.Now(), ExitCode: exitCode}
+	errOutput := stderr.String()
+	if command.ColorMode == ColorNever {
+		errOutput = StripANSI(errOutput)
+	}
+	return Result{Command: command, Output: "", Stderr: errOutput, Error: err, StartTime: startTime, EndTime: time.Now(), ExitCode: exitCode}
 }
 
 // ExecuteCommandInteractiveAttached runs an interactive command attached to the current TTY.
@@ -175,32 +384,16 @@ func ExecuteCommandInteractiveAttached(command model.Command) Result {
 		return Result{Command: command, Error: fmt.Errorf("empty command"), StartTime: startTime, EndTime: time.Now(), ExitCode: -1}
 	}
 
-	var cmd *exec.Cmd
-	if command.UseShell || command.Interactive {
-		shell := os.Getenv("SHELL")
-		if shell == "" {
-			shell = "bash"
-		}
-		cmd = exec.Command(shell, "-lc", command.Command)
-	} else {
-		parts := strings.Fields(command.Command)
-		if len(parts) == 0 {
-			return Result{Command: command, Error: fmt.Errorf("empty command"), StartTime: startTime, EndTime: time.Now(), ExitCode: -1}
-		}
-		cmd = exec.Command(parts[0], parts[1:]...)
-	}
-
-	if dir, derr := resolveWorkingDir(command); derr == nil && dir != "" {
-		cmd.Dir = dir
-	} else if derr != nil {
-		return Result{Command: command, Error: derr, StartTime: startTime, EndTime: time.Now(), ExitCode: -1}
+	cmd, err := newExecCmd(context.Background(), command, command.UseShell || command.Interactive)
+	if err != nil {
+		return Result{Command: command, Error: err, StartTime: startTime, EndTime: time.Now(), ExitCode: -1}
 	}
 
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
-	err := cmd.Run()
+	err = cmd.Run()
 
 	exitCode := 0
 	if err != nil {
@@ -217,24 +410,9 @@ func ExecuteCommandInteractiveAttached(command model.Command) Result {
 // StartInteractiveProcess starts a long-running process and returns the *exec.Cmd so caller can manage lifecycle.
 // Stdout/Stderr are streamed to the provided writer.
 func StartInteractiveProcess(command model.Command, stream io.Writer) (*exec.Cmd, error) {
-	var cmd *exec.Cmd
-	if command.UseShell || command.Interactive {
-		shell := os.Getenv("SHELL")
-		if shell == "" {
-			shell = "bash"
-		}
-		cmd = exec.Command(shell, "-lc", command.Command)
-	} else {
-		parts := strings.Fields(command.Command)
-		if len(parts) == 0 {
-			return nil, fmt.Errorf("empty command")
-		}
-		cmd = exec.Command(parts[0], parts[1:]...)
-	}
-	if dir, derr := resolveWorkingDir(command); derr == nil && dir != "" {
-		cmd.Dir = dir
-	} else if derr != nil {
-		return nil, derr
+	cmd, err := newExecCmd(context.Background(), command, command.UseShell || command.Interactive)
+	if err != nil {
+		return nil, err
 	}
 	cmd.Stdout = stream
 	cmd.Stderr = stream
@@ -245,36 +423,229 @@ func StartInteractiveProcess(command model.Command, stream io.Writer) (*exec.Cmd
 	return cmd, nil
 }
 
+// PTYProcess is a command running attached to a pseudo-terminal
+type PTYProcess struct {
+	Cmd    *exec.Cmd
+	PTY    *os.File      // Terminal side the command talks to: write input here, resize with pty.Setsize
+	copied chan struct{} // Closed once all output has been copied to the stream
+}
+
+// Wait waits for the command to exit and its output to be copied, then
+// closes the pseudo-terminal
+func (p *PTYProcess) Wait() error {
+	err := p.Cmd.Wait()
+	// Drain the terminal, unless a leftover child process keeps it open
+	select {
+	case <-p.copied:
+	case <-time.After(time.Second):
+	}
+	p.PTY.Close()
+	<-p.copied
+	return err
+}
+
 // StartInteractivePTY starts the command attached to a PTY so full-screen TUIs can render.
 // The PTY output is continuously copied to the provided stream until the process exits or the PTY is closed.
-func StartInteractivePTY(command model.Command, stream io.Writer) (*exec.Cmd, *os.File, error) {
-	var cmd *exec.Cmd
-	if command.UseShell || command.Interactive {
-		shell := os.Getenv("SHELL")
-		if shell == "" {
-			shell = "bash"
-		}
-		cmd = exec.Command(shell, "-lc", command.Command)
-	} else {
-		parts := strings.Fields(command.Command)
-		if len(parts) == 0 {
-			return nil, nil, fmt.Errorf("empty command")
-		}
-		cmd = exec.Command(parts[0], parts[1:]...)
-	}
-	if dir, derr := resolveWorkingDir(command); derr == nil && dir != "" {
-		cmd.Dir = dir
-	} else if derr != nil {
-		return nil, nil, derr
+func StartInteractivePTY(command model.Command, stream io.Writer) (*PTYProcess, error) {
+	cmd, err := newExecCmd(context.Background(), command, command.UseShell || command.Interactive)
+	if err != nil {
+		return nil, err
 	}
 	ptmx, err := pty.Start(cmd)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	p := &PTYProcess{Cmd: cmd, PTY: ptmx, copied: make(chan struct{})}
 	go func() {
 		_, _ = io.Copy(stream, ptmx)
+		close(p.copied)
 	}()
-	return cmd, ptmx, nil
+	return p, nil
+}
+
+// runSetup is how a command runs, resolved without starting it. newExecCmd
+// builds its *exec.Cmd from it; dry runs and exported scripts describe it.
+type runSetup struct {
+	argv       []string // The shell and its flags ending in the command, or the command's words
+	dir        string   // Working directory; empty for the current one
+	createdDir string   // Working directory that is created first, set instead of dir
+	env        []string // Variables added to our own environment, later ones overriding
+}
+
+// resolveRun resolves the argv, working directory and added environment of a
+// command: via the user's shell when viaShell is set (so pipes/quotes work),
+// otherwise by splitting on whitespace. It neither creates the working
+// directory nor reads the stdin file.
+func resolveRun(command model.Command, viaShell bool) (runSetup, error) {
+	var setup runSetup
+	if viaShell {
+		setup.argv = shellArgv(commandShell(command), command.Command)
+	} else {
+		setup.argv = strings.Fields(command.Command)
+		if len(setup.argv) == 0 {
+			return runSetup{}, fmt.Errorf("empty command")
+		}
+	}
+
+	// Resolve working directory according to command settings; a missing one
+	// that CreateWorkingDir allows is left for the run to create
+	if command.CreateWorkingDir {
+		if err := ValidateWorkingDir(command); err != nil {
+			return runSetup{}, err
+		}
+	}
+	probe := command
+	probe.CreateWorkingDir = false
+	dir, err := resolveWorkingDir(probe)
+	switch {
+	case err == nil:
+		setup.dir = dir
+	case command.CreateWorkingDir:
+		setup.createdDir, _ = expandWorkingDirPath(command.WorkingDirPath, strings.ToLower(strings.TrimSpace(command.WorkingDirMode)))
+	default:
+		return runSetup{}, err
+	}
+
+	// Apply variables from the command's env file on top of our environment
+	if strings.TrimSpace(command.EnvFile) != "" {
+		vars, err := loadEnvFile(command.EnvFile)
+		if err != nil {
+			return runSetup{}, err
+		}
+		setup.env = vars
+	}
+	// Inline variables come last so they override the env file
+	if len(command.Env) > 0 {
+		if err := ValidateEnv(command.Env); err != nil {
+			return runSetup{}, err
+		}
+		setup.env = append(setup.env, command.Env...)
+	}
+	// Most tools honor NO_COLOR (https://no-color.org)
+	if command.ColorMode == ColorNever {
+		setup.env = append(setup.env, "NO_COLOR=1")
+	}
+	return setup, nil
+}
+
+// newExecCmd builds the *exec.Cmd for a command resolved by resolveRun,
+// creating its working directory when needed and feeding it the stdin file
+func newExecCmd(ctx context.Context, command model.Command, viaShell bool) (*exec.Cmd, error) {
+	setup, err := resolveRun(command, viaShell)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, setup.argv[0], setup.argv[1:]...)
+	if viaShell {
+		setShellCmdLine(cmd, setup.argv)
+	}
+	// Don't wait forever for output pipes held open by orphaned children once killed
+	cmd.WaitDelay = time.Second
+
+	cmd.Dir = setup.dir
+	if setup.createdDir != "" {
+		if err := createWorkingDir(setup.createdDir); err != nil {
+			return nil, err
+		}
+		cmd.Dir = setup.createdDir
+	}
+	if len(setup.env) > 0 {
+		cmd.Env = append(os.Environ(), setup.env...)
+	}
+	// Feed the stdin file to the command; interactive runs replace this with the terminal
+	if strings.TrimSpace(command.StdinFile) != "" {
+		input, err := loadStdinFile(command.StdinFile)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Stdin = bytes.NewReader(input)
+	}
+
+	return cmd, nil
+}
+
+// loadStdinFile reads the file whose contents are piped to a command's stdin.
+// It is read up front so a missing file fails before anything is spawned.
+func loadStdinFile(path string) ([]byte, error) {
+	expanded, err := expandDirPlaceholders(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(expanded)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("stdin file not found: %s", expanded)
+		}
+		return nil, fmt.Errorf("failed to read stdin file: %w", err)
+	}
+	return data, nil
+}
+
+// ValidateEnv checks that every entry is a KEY=VALUE pair with a valid
+// variable name and a non-empty value
+func ValidateEnv(env []string) error {
+	for _, entry := range env {
+		key, value, ok := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("invalid env entry %q: expected KEY=VALUE", entry)
+		}
+		if !isEnvName(key) {
+			return fmt.Errorf("invalid env entry %q: %q is not a valid variable name", entry, key)
+		}
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("invalid env entry %q: value is empty", entry)
+		}
+	}
+	return nil
+}
+
+// isEnvName reports whether s is a portable environment variable name
+func isEnvName(s string) bool {
+	for i, r := range s {
+		if r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return s != ""
+}
+
+// loadEnvFile reads KEY=VALUE lines from a dotenv-style file. Blank lines and
+// # comments are skipped, an "export " prefix is allowed and matching quotes
+// around values are removed.
+func loadEnvFile(path string) ([]string, error) {
+	expanded, err := expandDirPlaceholders(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(expanded)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("env file not found: %s", expanded)
+		}
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	var vars []string
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", expanded, i+1)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars = append(vars, key+"="+value)
+	}
+	return vars, nil
 }
 
 // resolveWorkingDir decides the working directory based on per-command settings.
@@ -290,18 +661,25 @@ func resolveWorkingDir(command model.Command) (string, error) {
 			return "", fmt.Errorf("failed to resolve home directory: %w", err)
 		}
 		return home, nil
-	case "absolute":
+	case "absolute", "relative":
 		if strings.TrimSpace(command.WorkingDirPath) == "" {
-			return "", errors.New("working directory path is required when mode is 'absolute'")
+			return "", fmt.Errorf("working directory path is required when mode is '%s'", mode)
 		}
-		expanded, err := expandDirPlaceholders(command.WorkingDirPath)
+		expanded, err := expandWorkingDirPath(command.WorkingDirPath, mode)
 		if err != nil {
 			return "", err
 		}
 		if !filepath.IsAbs(expanded) {
 			return "", fmt.Errorf("working directory must be an absolute path: %s", expanded)
 		}
-		if fi, statErr := os.Stat(expanded); statErr != nil || !fi.IsDir() {
+		fi, statErr := os.Stat(expanded)
+		if os.IsNotExist(statErr) && command.CreateWorkingDir {
+			if err := createWorkingDir(expanded); err != nil {
+				return "", err
+			}
+			return expanded, nil
+		}
+		if statErr != nil || !fi.IsDir() {
 			return "", fmt.Errorf("working directory does not exist or is not a directory: %s", expanded)
 		}
 		return expanded, nil
@@ -310,6 +688,85 @@ func resolveWorkingDir(command model.Command) (string, error) {
 	}
 }
 
+// ValidateWorkingDir checks a command's working directory settings when it is
+// saved: the mode must be current, home, absolute or relative, and the path of
+// the last two must resolve to an existing directory unless CreateWorkingDir
+// is set
+func ValidateWorkingDir(command model.Command) error {
+	mode := strings.ToLower(strings.TrimSpace(command.WorkingDirMode))
+	switch mode {
+	case "", "current", "home":
+		return nil
+	case "absolute", "relative":
+	default:
+		return errors.New("WorkingDirMode must be current, home, absolute or relative")
+	}
+
+	if strings.TrimSpace(command.WorkingDirPath) == "" {
+		return fmt.Errorf("WorkingDirPath is required when WorkingDirMode is %s", mode)
+	}
+	expanded, err := expandWorkingDirPath(command.WorkingDirPath, mode)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(expanded) {
+		return fmt.Errorf("WorkingDirPath must be an absolute path: %s", expanded)
+	}
+	fi, err := os.Stat(expanded)
+	if os.IsNotExist(err) && command.CreateWorkingDir {
+		return nil
+	}
+	if err != nil || !fi.IsDir() {
+		return fmt.Errorf("WorkingDirPath does not exist or is not a directory: %s", expanded)
+	}
+	return nil
+}
+
+// createWorkingDir creates a missing working directory, checking that its
+// nearest existing ancestor is a directory we can write to
+func createWorkingDir(dir string) error {
+	parent := filepath.Dir(dir)
+	for {
+		if _, err := os.Stat(parent); err == nil || parent == filepath.Dir(parent) {
+			break
+		}
+		parent = filepath.Dir(parent)
+	}
+	if fi, err := os.Stat(parent); err != nil || !fi.IsDir() {
+		return fmt.Errorf("cannot create working directory %s: %s is not a directory", dir, parent)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("cannot create working directory %s: %s is not writable", dir, parent)
+		}
+		return fmt.Errorf("failed to create working directory: %w", err)
+	}
+	return nil
+}
+
+// expandWorkingDirPath expands placeholders in a working directory path and,
+// in relative mode, joins it onto the project root
+func expandWorkingDirPath(p, mode string) (string, error) {
+	expanded, err := expandDirPlaceholders(p)
+	if err != nil || mode != "relative" {
+		return expanded, err
+	}
+	if filepath.IsAbs(expanded) {
+		return "", fmt.Errorf("working directory must be a relative path when mode is 'relative': %s", expanded)
+	}
+	base, err := expandDirPlaceholders(projectRoot)
+	if err != nil {
+		return "", err
+	}
+	if base == "" {
+		if base, err = os.Getwd(); err != nil {
+			return "", fmt.Errorf("failed to resolve current working directory: %w", err)
+		}
+	}
+	return filepath.Join(base, expanded), nil
+}
+
 // expandDirPlaceholders expands ~, $HOME and ${cwd} in the provided path.
 func expandDirPlaceholders(p string) (string, error) {
 	// Environment variables like $HOME
@@ -340,19 +797,52 @@ func expandDirPlaceholders(p string) (string, error) {
 	return p, nil
 }
 
-// FormatOutput formats the execution result for display
+// outputMarker separates the FormatOutput header from the command output
+const outputMarker = "--- Output ---\n"
+
+// stderrMarker starts the stderr section of FormatOutputSeparated
+const stderrMarker = "--- Stderr ---\n"
+
+// FormatOutput formats the execution result for display, with stdout and
+// stderr combined as the command wrote them
 func FormatOutput(result Result) string {
+	return formatOutput(result, false)
+}
+
+// FormatOutputSeparated formats the execution result like FormatOutput, but
+// moves stderr out of the output into its own section after it
+func FormatOutputSeparated(result Result) string {
+	return formatOutput(result, true)
+}
+
+func formatOutput(result Result, separateStderr bool) string {
 	var sb strings.Builder
 
 	// Command info
 	sb.WriteString(fmt.Sprintf("Command: %s\n", result.Command.Command))
 	sb.WriteString(fmt.Sprintf("Started: %s\n", result.StartTime.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("Duration: %s\n", result.EndTime.Sub(result.StartTime)))
-	sb.WriteString(fmt.Sprintf("Exit Code: %d\n", result.ExitCode))
-	sb.WriteString("\n--- Output ---\n")
+	switch result.ExitCode {
+	case ExitCodeTimeout:
+		sb.WriteString(fmt.Sprintf("Exit Code: %d (timed out)\n", result.ExitCode))
+	case ExitCodeCancelled:
+		sb.WriteString(fmt.Sprintf("Exit Code: %d (cancelled)\n", result.ExitCode))
+	default:
+		sb.WriteString(fmt.Sprintf("Exit Code: %d\n", result.ExitCode))
+	}
+	if result.Attempts > 1 {
+		sb.WriteString(fmt.Sprintf("Attempts: %d\n", result.Attempts))
+	}
+	sb.WriteString("\n" + outputMarker)
 
 	// Command output
-	sb.WriteString(result.Output)
+	if separateStderr && result.Stderr != "" {
+		sb.WriteString(strings.TrimRight(withoutLines(result.Output, result.Stderr), "\n"))
+		sb.WriteString("\n\n" + stderrMarker)
+		sb.WriteString(strings.TrimSuffix(result.Stderr, "\n"))
+	} else {
+		sb.WriteString(result.Output)
+	}
 
 	// Add error if present
 	if result.Error != nil {
@@ -362,3 +852,22 @@ func FormatOutput(result Result) string {
 
 	return sb.String()
 }
+
+// withoutLines removes the lines of remove from output, which contains them
+// interleaved with other lines (as the combined output contains stderr); each
+// line of remove takes out its first remaining occurrence
+func withoutLines(output, remove string) string {
+	pending := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSuffix(remove, "\n"), "\n") {
+		pending[line]++
+	}
+	var kept []string
+	for _, line := range strings.Split(output, "\n") {
+		if pending[line] > 0 {
+			pending[line]--
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,112 @@
+package update
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+func TestShellArgv(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{"/bin/bash", []string{"/bin/bash", "-lc", "echo hi"}},
+		{"/usr/bin/zsh", []string{"/usr/bin/zsh", "-lc", "echo hi"}},
+		{"/usr/local/bin/fish", []string{"/usr/local/bin/fish", "-c", "echo hi"}},
+		{"sh", []string{"sh", "-c", "echo hi"}},
+		{"cmd.exe", []string{"cmd.exe", "/S", "/C", "echo hi"}},
+		{"pwsh", []string{"pwsh", "-NoProfile", "-Command", "echo hi"}},
+		{"/opt/bin/nu", []string{"/opt/bin/nu", "-lc", "echo hi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			if got := shellArgv(tt.shell, "echo hi"); !slices.Equal(got, tt.want) {
+				t.Errorf("shellArgv(%q) = %q, want %q", tt.shell, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetShellFlags(t *testing.T) {
+	saved := maps.Clone(ShellFlags)
+	t.Cleanup(func() { ShellFlags = saved })
+
+	SetShellFlags(map[string][]string{"bash": {"-c"}, "nu": {"--login", "-c"}})
+
+	if got, want := shellArgv("/bin/bash", "ls"), []string{"/bin/bash", "-c", "ls"}; !slices.Equal(got, want) {
+		t.Errorf("overridden bash: got %q, want %q", got, want)
+	}
+	if got, want := shellArgv("nu", "ls"), []string{"nu", "--login", "-c", "ls"}; !slices.Equal(got, want) {
+		t.Errorf("added nu: got %q, want %q", got, want)
+	}
+	if got, want := shellArgv("zsh", "ls"), []string{"zsh", "-lc", "ls"}; !slices.Equal(got, want) {
+		t.Errorf("untouched zsh: got %q, want %q", got, want)
+	}
+}
+
+func TestNewExecCmdUsesCommandShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix shells")
+	}
+	command := model.Command{Command: "echo $HOME | wc -c", Shell: "/bin/sh"}
+	cmd, err := newExecCmd(context.Background(), command, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/bin/sh", "-c", "echo $HOME | wc -c"}; !slices.Equal(cmd.Args, want) {
+		t.Errorf("Args = %q, want %q", cmd.Args, want)
+	}
+
+	// Without the shell the command line is split into words
+	cmd, err = newExecCmd(context.Background(), model.Command{Command: "ls -la /tmp"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ls", "-la", "/tmp"}; !slices.Equal(cmd.Args, want) {
+		t.Errorf("Args = %q, want %q", cmd.Args, want)
+	}
+}
+
+func TestResolveRunLeavesTheWorkingDirToTheRun(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "build")
+	command := model.Command{
+		Command:          "make",
+		WorkingDirMode:   "absolute",
+		WorkingDirPath:   dir,
+		CreateWorkingDir: true,
+		Env:              []string{"STAGE=prod"},
+		ColorMode:        ColorNever,
+	}
+	setup, err := resolveRun(command, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if setup.createdDir != dir || setup.dir != "" {
+		t.Errorf("dir = %q, createdDir = %q, want only %q to be created", setup.dir, setup.createdDir, dir)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("resolving the run created the working directory")
+	}
+	if want := []string{"STAGE=prod", "NO_COLOR=1"}; !slices.Equal(setup.env, want) {
+		t.Errorf("env = %q, want only the added %q", setup.env, want)
+	}
+
+	// A real run creates it
+	cmd, err := newExecCmd(context.Background(), command, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Dir != dir {
+		t.Errorf("Dir = %q, want %q", cmd.Dir, dir)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("the run didn't create the working directory: %v", err)
+	}
+}
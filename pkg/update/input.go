@@ -0,0 +1,35 @@
+package update
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typedText returns the text a key press adds to a text input: a typed
+// character, a space, or the whole text of a paste. Newlines in a paste are
+// kept when multiline is set and become spaces otherwise; a trailing one is
+// dropped, so pasting a copied line doesn't submit or extend it.
+func typedText(msg tea.KeyMsg, multiline bool) string {
+	switch {
+	case msg.Type == tea.KeySpace && !msg.Alt:
+		return " "
+	case msg.Type != tea.KeyRunes || msg.Alt && !msg.Paste:
+		return ""
+	}
+	text := strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(string(msg.Runes))
+	text = strings.TrimRight(text, "\n")
+	if !multiline {
+		text = strings.ReplaceAll(text, "\n", " ")
+	}
+	return text
+}
+
+// trimLastRune removes the last character of s, which may take several bytes
+func trimLastRune(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	return string(runes[:len(runes)-1])
+}
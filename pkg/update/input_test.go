@@ -0,0 +1,107 @@
+package update
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+func TestTypedText(t *testing.T) {
+	paste := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s), Paste: true}
+	}
+	tests := []struct {
+		name      string
+		msg       tea.KeyMsg
+		multiline bool
+		want      string
+	}{
+		{"character", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("é")}, false, "é"},
+		{"space", tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, false, " "},
+		{"alt+key is a shortcut", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x"), Alt: true}, false, ""},
+		{"alt+space is a shortcut", tea.KeyMsg{Type: tea.KeySpace, Alt: true}, false, ""},
+		{"other keys", tea.KeyMsg{Type: tea.KeyUp}, false, ""},
+		{"paste", paste("deploy wéb"), false, "deploy wéb"},
+		{"paste of a copied line", paste("make build\n"), false, "make build"},
+		{"multiline paste on one line", paste("echo a\r\necho b\n"), false, "echo a echo b"},
+		{"multiline paste", paste("echo a\r\necho b\recho c\n"), true, "echo a\necho b\necho c"},
+		{"paste starting with escape", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x"), Alt: true, Paste: true}, false, "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := typedText(tt.msg, tt.multiline); got != tt.want {
+				t.Errorf("typedText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimLastRune(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"a", ""},
+		{"café", "caf"},
+		{"日本", "日"},
+		{"deploy 🚀", "deploy "},
+	}
+	for _, tt := range tests {
+		if got := trimLastRune(tt.in); got != tt.want {
+			t.Errorf("trimLastRune(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// inputs are the text inputs of the list view: how to open each and read
+// what was typed into it
+var inputs = []struct {
+	name string
+	open func(model.Model) model.Model
+	text func(model.Model) string
+}{
+	{"filter", func(m model.Model) model.Model { return press(m, "f") }, func(m model.Model) string { return m.InputBuffer }},
+	{"palette", func(m model.Model) model.Model {
+		m, _ = Update(tea.KeyMsg{Type: tea.KeyCtrlP}, m)
+		return m
+	}, func(m model.Model) string { return m.PaletteQuery }},
+	{"form", func(m model.Model) model.Model {
+		m = press(m, "n")
+		m.ActiveFormField = model.FieldCommand
+		return press(m, "enter")
+	}, func(m model.Model) string { return m.FormInputBuffer }},
+}
+
+func TestBackspaceRemovesWholeCharacters(t *testing.T) {
+	for _, input := range inputs {
+		t.Run(input.name, func(t *testing.T) {
+			m := input.open(testModel(model.Command{ID: "1", Name: "café", Command: "ls"}))
+			m = press(m, "café")
+			m = press(m, "backspace")
+			if got := input.text(m); got != "caf" {
+				t.Errorf("after backspace = %q, want %q", got, "caf")
+			}
+			m = press(m, "日本")
+			m = press(m, "backspace")
+			if got := input.text(m); got != "caf日" || !utf8.ValidString(got) {
+				t.Errorf("after backspace = %q, want %q", got, "caf日")
+			}
+		})
+	}
+}
+
+func TestPasteIsCapturedWhole(t *testing.T) {
+	for _, input := range inputs {
+		t.Run(input.name, func(t *testing.T) {
+			m := input.open(testModel(model.Command{ID: "1", Name: "deploy", Command: "ls"}))
+			m, _ = Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("deploy wéb "), Paste: true}, m)
+			m = press(m, "x")
+			if got := input.text(m); got != "deploy wéb x" {
+				t.Errorf("after paste = %q, want %q", got, "deploy wéb x")
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package update
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// logsDir returns the directory background runs write their logs to
+func logsDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".go-recipe", "logs"), nil
+}
+
+// listLogFiles returns the background logs, most recently modified first.
+// A missing logs directory yields no files.
+func listLogFiles() ([]model.LogFile, error) {
+	dir, err := logsDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []model.LogFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, model.LogFile{
+			Name:    entry.Name(),
+			Path:    filepath.Join(dir, entry.Name()),
+			ModTime: info.ModTime(),
+			Size:    info.Size(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime.After(files[j].ModTime) })
+	return files, nil
+}
+
+// openLogs shows the log list
+func openLogs(m model.Model) (model.Model, tea.Cmd) {
+	files, err := listLogFiles()
+	if err != nil {
+		m.Error = fmt.Sprintf("Failed to list logs: %v", err)
+		return m, nil
+	}
+	m.ShowLogs = true
+	m.LogFiles = files
+	m.LogIndex = 0
+	return m, nil
+}
+
+// handleLogsKeyPress navigates the log list and opens the selected log
+func handleLogsKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "l":
+		m.ShowLogs = false
+		m.LogFiles = nil
+	case "up", "k":
+		if m.LogIndex > 0 {
+			m.LogIndex--
+		}
+	case "down", "j":
+		if m.LogIndex < len(m.LogFiles)-1 {
+			m.LogIndex++
+		}
+	case "r":
+		// Refresh the list, e.g. after starting more background runs
+		return openLogs(m)
+	case "enter":
+		if m.LogIndex < len(m.LogFiles) {
+			return viewLog(m.LogFiles[m.LogIndex], m)
+		}
+	}
+	return m, nil
+}
+
+// viewLog shows a log file in the output view and keeps polling it, so a
+// background run that is still writing can be tailed live
+func viewLog(file model.LogFile, m model.Model) (model.Model, tea.Cmd) {
+	m.Executing = true
+	m.ExecutingCommand = &model.Command{Name: file.Name, Command: file.Path}
+	m.ExecutionOutput = ""
+	m.StreamedOutput = model.NewOutputBuffer(m.Settings.OutputMaxLines)
+	m.OutputScrollPosition = 0
+	m.FollowOutput = true
+	m.SelectionActive = false
+	stopWatching(&m)
+	m.Spinning = false
+	m.ViewingLastResult = false
+	m.ViewingDryRun = false
+	m.AlternateOutput = ""
+	m.ExecutionStart = time.Time{}
+	stopTailing(&m)
+	m.ExecutionLogPath = file.Path
+	return handleStreamPoll(m)
+}
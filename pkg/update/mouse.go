@@ -0,0 +1,93 @@
+package update
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// wheelLines is how many output lines one mouse wheel step scrolls
+const wheelLines = 3
+
+// handleMouse scrolls the execution output with the wheel, and in the command
+// list moves the selection with the wheel and selects or runs a clicked row
+func handleMouse(msg tea.MouseMsg, m model.Model) (model.Model, tea.Cmd) {
+	if m.Executing {
+		if m.SelectionActive {
+			return m, nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.OutputScrollPosition = max(m.OutputScrollPosition-wheelLines, 0)
+			m.FollowOutput = false
+		case tea.MouseButtonWheelDown:
+			maxScroll := maxOutputScroll(m)
+			m.OutputScrollPosition = min(m.OutputScrollPosition+wheelLines, maxScroll)
+			m.FollowOutput = m.OutputScrollPosition >= maxScroll
+		}
+		return m, nil
+	}
+
+	// Only the plain list reacts; prompts, the form and other screens own the keyboard
+	if m.CurrentMode != model.ModeNormal || m.ShowForm || m.ShowHelp || m.ShowLogs || m.ShowTasks || m.ShowCategories {
+		return m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if m.SelectedIndex > 0 {
+			m.SelectedIndex--
+		}
+	case tea.MouseButtonWheelDown:
+		if m.SelectedIndex < len(m.VisibleCommands)-1 {
+			m.SelectedIndex++
+		}
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		index, ok := commandAtLine(m, msg.Y)
+		if !ok {
+			return m, nil
+		}
+		m.Error = ""
+		m.Info = ""
+		// A second click on the selected row (or a double-click) runs it
+		if index == m.SelectedIndex {
+			return requestExecute(m.VisibleCommands[index], m)
+		}
+		m.SelectedIndex = index
+	}
+	scrollListToSelection(&m)
+	return m, nil
+}
+
+// commandAtLine maps a screen line in the main view to the index of the
+// visible command drawn there. It mirrors the layout of view.renderMain: the
+// title, categories, optional tags and filter lines, then the list window
+// with its category headers, where the selected command takes three lines
+// and, in the detailed list, others with a description two.
+func commandAtLine(m model.Model, y int) (int, bool) {
+	scrollListToSelection(&m)
+
+	line := m.ListTop()
+	if m.ListOffset > 0 {
+		line++ // "↑ N more" indicator
+	}
+
+	end := m.ListWindowEnd(m.ListOffset, m.ListRows())
+	for i := m.ListOffset; i < end; i++ {
+		if m.StartsGroup(i, m.ListOffset) {
+			line++ // category header
+		}
+		height := m.RowLines(i)
+		if i == m.SelectedIndex {
+			height = 3 // name, command and description
+		}
+		if y >= line && y < line+height {
+			return i, true
+		}
+		line += height
+	}
+	return 0, false
+}
@@ -0,0 +1,46 @@
+package update
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// notifyFinished shows a desktop notification for a finished background run.
+// Failures (e.g. no notifier installed on a headless machine) are ignored.
+func notifyFinished(command model.Command, result Result) {
+	title := "go-recipe: " + command.Name
+	body := "Finished successfully"
+	if result.Error != nil || result.ExitCode != 0 {
+		body = fmt.Sprintf("Failed (exit code %d)", result.ExitCode)
+	}
+
+	// Run fails without starting anything when the notifier isn't installed
+	_ = notifyCmd(title, body).Run()
+}
+
+// notifyCmd builds the platform's notification command
+func notifyCmd(title, body string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		return exec.Command("osascript", "-e", script)
+	case "windows":
+		script := fmt.Sprintf(`[reflection.assembly]::loadwithpartialname('System.Windows.Forms') | Out-Null; `+
+			`$n = New-Object System.Windows.Forms.NotifyIcon; $n.Icon = [System.Drawing.SystemIcons]::Information; `+
+			`$n.Visible = $true; $n.ShowBalloonTip(5000, '%s', '%s', 'Info'); Start-Sleep -Seconds 6; $n.Dispose()`,
+			strings.ReplaceAll(title, "'", "''"), strings.ReplaceAll(body, "'", "''"))
+		return exec.Command("powershell", "-NoProfile", "-Command", script)
+	default:
+		return exec.Command("notify-send", title, body)
+	}
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,188 @@
+package update
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// placeholderHistoryLimit caps the remembered values per placeholder
+const placeholderHistoryLimit = 10
+
+// placeholderPattern matches {{name}} placeholders, allowing spaces inside the braces
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// parsePlaceholders returns the placeholder names in cmd, in order of first appearance
+func parsePlaceholders(cmd string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(cmd, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// substitutePlaceholders replaces each placeholder in cmd with its value
+func substitutePlaceholders(cmd string, values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(cmd, func(s string) string {
+		name := placeholderPattern.FindStringSubmatch(s)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return s
+	})
+}
+
+// FillPlaceholders fills in the command's placeholders from values, for runs
+// outside the TUI where nobody can be prompted. Every placeholder needs a
+// value and every value a placeholder, so a typo doesn't go unnoticed.
+func FillPlaceholders(command model.Command, values map[string]string) (model.Command, error) {
+	names := parsePlaceholders(command.Command)
+	var missing []string
+	for _, name := range names {
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return command, fmt.Errorf("%q needs a value for %s", command.Name, strings.Join(missing, ", "))
+	}
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if !slices.Contains(names, name) {
+			return command, fmt.Errorf("%q has no placeholder {{%s}}", command.Name, name)
+		}
+	}
+	command.Command = substitutePlaceholders(command.Command, values)
+	return command, nil
+}
+
+// promptPlaceholders starts asking for the value of each placeholder of the command
+func promptPlaceholders(command model.Command, names []string, m model.Model) (model.Model, tea.Cmd) {
+	// The prompt lives in the list view, so leave any finished output
+	m.Executing = false
+	m.ExecutingCommand = nil
+	stopWatching(&m)
+
+	m.CurrentMode = model.ModePlaceholder
+	m.PlaceholderCommand = &command
+	m.PlaceholderNames = names
+	m.PlaceholderValues = map[string]string{}
+	m.PlaceholderDryRun = false
+	m.PlaceholderWatch = false
+	m = startPlaceholder(m)
+	return m, nil
+}
+
+// startPlaceholder prefills the prompt for the next placeholder with its most recent value
+func startPlaceholder(m model.Model) model.Model {
+	name := m.PlaceholderNames[len(m.PlaceholderValues)]
+	m.InputBuffer = ""
+	m.PlaceholderPick = -1
+	if history := m.Settings.PlaceholderHistory[name]; len(history) > 0 {
+		m.InputBuffer = history[0]
+		m.PlaceholderPick = 0
+	}
+	return m
+}
+
+// rememberPlaceholder moves value to the front of the placeholder's history,
+// dropping older copies and entries past the limit
+func rememberPlaceholder(history []string, value string) []string {
+	if value == "" {
+		return history
+	}
+	updated := []string{value}
+	for _, v := range history {
+		if v != value && len(updated) < placeholderHistoryLimit {
+			updated = append(updated, v)
+		}
+	}
+	return updated
+}
+
+// handlePlaceholderMode collects placeholder values one at a time and runs the
+// command once the last one is entered
+func handlePlaceholderMode(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.CurrentMode = model.ModeNormal
+		m.PlaceholderCommand = nil
+		m.PlaceholderNames = nil
+		m.PlaceholderValues = nil
+		m.InputBuffer = ""
+	case "enter":
+		name := m.PlaceholderNames[len(m.PlaceholderValues)]
+		m.PlaceholderValues[name] = m.InputBuffer
+		if len(m.PlaceholderValues) < len(m.PlaceholderNames) {
+			return startPlaceholder(m), nil
+		}
+
+		// Remember the values as suggestions for the next run
+		if m.Settings.PlaceholderHistory == nil {
+			m.Settings.PlaceholderHistory = map[string][]string{}
+		}
+		for name, value := range m.PlaceholderValues {
+			m.Settings.PlaceholderHistory[name] = rememberPlaceholder(m.Settings.PlaceholderHistory[name], value)
+		}
+		saveErr := config.SaveSettings(m.Settings)
+
+		command := *m.PlaceholderCommand
+		command.Command = substitutePlaceholders(command.Command, m.PlaceholderValues)
+		m.CurrentMode = model.ModeNormal
+		m.PlaceholderCommand = nil
+		m.PlaceholderNames = nil
+		m.PlaceholderValues = nil
+		m.InputBuffer = ""
+
+		var cmd tea.Cmd
+		if m.PlaceholderDryRun {
+			m, cmd = showDryRun(command, m)
+		} else if m.PlaceholderWatch {
+			// The values are filled in once and reused by every run
+			m = promptWatchInterval(command, m)
+		} else {
+			m, cmd = executeCommand(command, m)
+		}
+		if saveErr != nil && m.Error == "" {
+			m.Error = fmt.Sprintf("Failed to save settings: %v", saveErr)
+		}
+		return m, cmd
+	case "up", "down":
+		// Step through the history: up goes to older values, down to newer ones
+		history := m.Settings.PlaceholderHistory[m.PlaceholderNames[len(m.PlaceholderValues)]]
+		if len(history) == 0 {
+			return m, nil
+		}
+		if msg.String() == "up" && m.PlaceholderPick < len(history)-1 {
+			m.PlaceholderPick++
+		} else if msg.String() == "down" && m.PlaceholderPick > 0 {
+			m.PlaceholderPick--
+		}
+		if m.PlaceholderPick < 0 {
+			m.PlaceholderPick = 0
+		}
+		m.InputBuffer = history[m.PlaceholderPick]
+	case "ctrl+u":
+		m.InputBuffer = ""
+		m.PlaceholderPick = -1
+	case "backspace":
+		m.InputBuffer = trimLastRune(m.InputBuffer)
+		m.PlaceholderPick = -1
+	default:
+		if text := typedText(msg, false); text != "" {
+			m.InputBuffer += text
+			m.PlaceholderPick = -1
+		}
+	}
+	return m, nil
+}
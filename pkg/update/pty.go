@@ -0,0 +1,154 @@
+package update
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/creack/pty"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// startPTYSession runs an interactive command attached to a pseudo-terminal
+// inside the execution view: its output is drawn on a model.Screen shown in
+// place of the output, and keys typed meanwhile are written to it (see
+// handlePTYKeyPress)
+func startPTYSession(command model.Command, m model.Model) (model.Model, tea.Cmd) {
+	tmpFile, err := os.CreateTemp("", "go-recipe-pty-*.log")
+	if err != nil {
+		m.Error = fmt.Sprintf("Failed to create temp log: %v", err)
+		m.Executing = false
+		m.ExecutingCommand = nil
+		return m, nil
+	}
+	// Mask secrets before they are written to the log; prompts are written
+	// out at once since the command waits for an answer
+	var out io.Writer = tmpFile
+	if hasRedaction(command, m.Settings) {
+		settings := m.Settings
+		out = &redactWriter{w: tmpFile, redact: func(s string) string { return RedactOutput(s, command, settings) }, eager: true}
+	}
+	proc, err := StartInteractivePTY(command, out)
+	if err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		m.Error = fmt.Sprintf("Failed to start %q in a pseudo-terminal: %v", command.Name, err)
+		m.Executing = false
+		m.ExecutingCommand = nil
+		return m, nil
+	}
+
+	stopTailing(&m)
+	removeTempLog(&m)
+	m.ExecutionLogPath = tmpFile.Name()
+	m.ExecutionTempLog = tmpFile.Name()
+	m.ExecutionStart = time.Now()
+	m.ExecutionElapsed = 0
+	m.Spinning = true
+	m.FollowOutput = true
+	m.StreamedOutput = model.NewOutputBuffer(m.Settings.OutputMaxLines)
+	m.PTY = proc.PTY
+	cols, rows := ptySize(m)
+	m.Screen = model.NewScreen(cols, rows, m.Settings.OutputMaxLines)
+	resizePTY(m)
+	// Killed with ctrl+] since every other key goes to the command
+	m.ExecutionCancel = func() { _ = proc.Cmd.Process.Kill() }
+
+	started := m.ExecutionStart
+	wait := func() tea.Msg {
+		err := proc.Wait()
+		tmpFile.Close()
+		exitCode := 0
+		if err != nil {
+			if ee, ok := err.(*exec.ExitError); ok {
+				exitCode = ee.ExitCode()
+			} else {
+				exitCode = -1
+			}
+		}
+		return CommandResultMsg{Result: Result{Command: command, Error: err, StartTime: started, EndTime: time.Now(), ExitCode: exitCode}}
+	}
+	poll := tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg { return StreamPollMsg{} })
+	spin := tea.Tick(120*time.Millisecond, func(time.Time) tea.Msg { return SpinnerTickMsg{} })
+	elapsed := tea.Tick(time.Second, func(time.Time) tea.Msg { return ElapsedTickMsg{} })
+	return m, tea.Batch(wait, poll, spin, elapsed)
+}
+
+// resizePTY gives the pseudo-terminal of a running interactive command, and
+// the screen it draws on, the size of the execution view's output area
+func resizePTY(m model.Model) {
+	if m.PTY == nil {
+		return
+	}
+	cols, rows := ptySize(m)
+	_ = pty.Setsize(m.PTY, &pty.Winsize{Rows: uint16(rows), Cols: uint16(cols)})
+	if m.Screen != nil {
+		m.Screen.Resize(cols, rows)
+	}
+}
+
+// ptySize returns the columns and rows of the execution view's output area,
+// inside its padding
+func ptySize(m model.Model) (cols, rows int) {
+	return max(m.Width-4, 20), outputVisibleLines(m.Height)
+}
+
+// handlePTYKeyPress writes a key to the command running in the pseudo-terminal.
+// Only ctrl+] is kept, to kill a command that doesn't quit.
+func handlePTYKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	if msg.String() == "ctrl+]" {
+		if m.ExecutionCancel != nil {
+			m = cancelExecution(m)
+		}
+		return m, nil
+	}
+	if input := ptyInput(msg); len(input) > 0 {
+		_, _ = m.PTY.Write(input)
+	}
+	// Show what the command echoes back
+	m.FollowOutput = true
+	return m, nil
+}
+
+// ptyInput returns the bytes a terminal sends for a key
+func ptyInput(msg tea.KeyMsg) []byte {
+	var input []byte
+	if msg.Alt {
+		input = append(input, '\x1b')
+	}
+	switch msg.Type {
+	case tea.KeyRunes:
+		return append(input, string(msg.Runes)...)
+	case tea.KeySpace:
+		return append(input, ' ')
+	case tea.KeyUp:
+		return append(input, "\x1b[A"...)
+	case tea.KeyDown:
+		return append(input, "\x1b[B"...)
+	case tea.KeyRight:
+		return append(input, "\x1b[C"...)
+	case tea.KeyLeft:
+		return append(input, "\x1b[D"...)
+	case tea.KeyHome:
+		return append(input, "\x1b[H"...)
+	case tea.KeyEnd:
+		return append(input, "\x1b[F"...)
+	case tea.KeyPgUp:
+		return append(input, "\x1b[5~"...)
+	case tea.KeyPgDown:
+		return append(input, "\x1b[6~"...)
+	case tea.KeyDelete:
+		return append(input, "\x1b[3~"...)
+	case tea.KeyShiftTab:
+		return append(input, "\x1b[Z"...)
+	}
+	// Control keys, enter, tab, esc and backspace carry their own byte
+	if msg.Type >= 0 && msg.Type <= 127 {
+		return append(input, byte(msg.Type))
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package update
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// redactMask replaces every redacted match
+const redactMask = "****"
+
+// redactPatterns caches compiled redaction patterns; invalid ones map to nil
+var (
+	redactPatternsMu sync.Mutex
+	redactPatterns   = map[string]*regexp.Regexp{}
+)
+
+// compileRedactPattern returns the compiled pattern, or nil if it is invalid
+func compileRedactPattern(pattern string) *regexp.Regexp {
+	redactPatternsMu.Lock()
+	defer redactPatternsMu.Unlock()
+	re, ok := redactPatterns[pattern]
+	if !ok {
+		re, _ = regexp.Compile(pattern)
+		redactPatterns[pattern] = re
+	}
+	return re
+}
+
+// validateRedactPatterns reports the first pattern that doesn't compile
+func validateRedactPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid redact pattern %q: %v", p, err)
+		}
+	}
+	return nil
+}
+
+// RedactOutput masks matches of the command's patterns and the global
+// patterns from settings in text. Invalid patterns are ignored.
+func RedactOutput(text string, command model.Command, settings model.Settings) string {
+	for _, patterns := range [][]string{settings.RedactPatterns, command.Redact} {
+		for _, p := range patterns {
+			if re := compileRedactPattern(p); re != nil {
+				text = re.ReplaceAllString(text, redactMask)
+			}
+		}
+	}
+	return text
+}
+
+// hasRedaction reports whether any redaction pattern applies to the command
+func hasRedaction(command model.Command, settings model.Settings) bool {
+	return len(command.Redact) > 0 || len(settings.RedactPatterns) > 0
+}
+
+// redactWriter redacts output line by line before passing it on, so
+// secrets never reach the underlying writer (e.g. a background log file)
+type redactWriter struct {
+	w      io.Writer
+	redact func(string) string
+	buf    []byte
+	eager  bool // Write partial lines out at once, e.g. prompts in a pseudo-terminal
+}
+
+// Write buffers p and writes out every complete line, redacted. An eager
+// writer also writes out what is left, which misses secrets split across
+// writes but never holds back a prompt waiting for input.
+func (r *redactWriter) Write(p []byte) (int, error) {
+	r.buf = append(r.buf, p...)
+	if r.eager {
+		return len(p), r.Flush()
+	}
+	if i := bytes.LastIndexByte(r.buf, '\n'); i >= 0 {
+		if _, err := io.WriteString(r.w, r.redact(string(r.buf[:i+1]))); err != nil {
+			return 0, err
+		}
+		r.buf = r.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes out a trailing partial line
+func (r *redactWriter) Flush() error {
+	if len(r.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(r.w, r.redact(string(r.buf)))
+	r.buf = nil
+	return err
+}
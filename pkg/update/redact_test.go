@@ -0,0 +1,40 @@
+package update
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactWriter(t *testing.T) {
+	mask := func(s string) string { return strings.ReplaceAll(s, "s3cret", redactMask) }
+	chunks := []string{"token=s3cret\npass", "word: "}
+
+	tests := []struct {
+		name    string
+		eager   bool
+		written string // After the chunks, before Flush
+	}{
+		{"lines", false, "token=****\n"},
+		{"eager", true, "token=****\npassword: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			w := &redactWriter{w: &out, redact: mask, eager: tt.eager}
+			for _, chunk := range chunks {
+				if _, err := w.Write([]byte(chunk)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if out.String() != tt.written {
+				t.Errorf("written = %q, want %q", out.String(), tt.written)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(out.String(), "password: ") {
+				t.Errorf("Flush left out the partial line: %q", out.String())
+			}
+		})
+	}
+}
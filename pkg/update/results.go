@@ -0,0 +1,90 @@
+package update
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// saveResult stores the result as the command's last result
+func saveResult(result Result) error {
+	record := model.ExecutionRecord{
+		CommandID: result.Command.ID,
+		Command:   result.Command.Command,
+		Output:    result.Output,
+		ExitCode:  result.ExitCode,
+		StartTime: result.StartTime,
+		EndTime:   result.EndTime,
+	}
+	if result.Error != nil {
+		record.Error = result.Error.Error()
+	}
+	return config.SaveResult(record)
+}
+
+// RecordHistory appends a finished run to the execution history
+func RecordHistory(result Result) error {
+	return config.AppendHistory(model.HistoryEntry{
+		CommandID:  result.Command.ID,
+		Name:       result.Command.Name,
+		StartTime:  result.StartTime,
+		EndTime:    result.EndTime,
+		ExitCode:   result.ExitCode,
+		DurationMs: result.EndTime.Sub(result.StartTime).Milliseconds(),
+	})
+}
+
+// openLastResult shows the stored last result of the command without running it
+func openLastResult(command model.Command, m model.Model) (model.Model, tea.Cmd) {
+	record, ok, err := config.LoadResult(command.ID)
+	if err != nil {
+		m.Error = err.Error()
+		return m, nil
+	}
+	if !ok {
+		if m.Settings.SaveResults {
+			m.Error = fmt.Sprintf("No stored result for %q yet", command.Name)
+		} else {
+			m.Error = "No stored result. Set save_results in settings.json to keep the last result of each run"
+		}
+		return m, nil
+	}
+
+	result := Result{
+		Command:   command,
+		Output:    displayableOutput(record.Output),
+		StartTime: record.StartTime,
+		EndTime:   record.EndTime,
+		ExitCode:  record.ExitCode,
+	}
+	result.Command.Command = record.Command
+	if record.Error != "" {
+		result.Error = errors.New(record.Error)
+	}
+
+	m.Executing = true
+	m.ExecutingCommand = &result.Command
+	m.ViewingLastResult = true
+	m.ViewingDryRun = false
+	m.AlternateOutput = ""
+	m.StreamedOutput = boundedOutput(result.Output, m.Settings)
+	result.Output = bufferedText(m.StreamedOutput)
+	m.ExecutionOutput = FormatOutput(result)
+	if record.Truncated {
+		m.ExecutionOutput = fmt.Sprintf("(output truncated to the last %d KB)\n\n", config.MaxResultOutput/1024) + m.ExecutionOutput
+	}
+	m.OutputScrollPosition = 0
+	m.FollowOutput = false
+	m.SelectionActive = false
+	stopWatching(&m)
+	m.Spinning = false
+	m.ExecutionStart = record.StartTime
+	m.ExecutionElapsed = record.EndTime.Sub(record.StartTime)
+	m.BatchTotal = 0
+	m.BatchOutput = ""
+	return m, nil
+}
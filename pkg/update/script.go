@@ -0,0 +1,78 @@
+package update
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// ShellScript turns a command into a standalone bash script that runs it the
+// way go-recipe would: in its working directory, with its environment, hooks
+// and stdin file. Commands with UseShell are embedded verbatim; others are
+// quoted word by word so the shell doesn't interpret them, just like a direct
+// run.
+func ShellScript(command model.Command) (string, error) {
+	// Exporting must not create the working directory, the script does, and
+	// the stdin file is only read when the script runs
+	viaShell := command.UseShell || command.Interactive
+	setup, err := resolveRun(command, viaShell)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("#!/usr/bin/env bash\n")
+	fmt.Fprintf(&sb, "# %s", command.Name)
+	if command.Description != "" {
+		fmt.Fprintf(&sb, ": %s", command.Description)
+	}
+	sb.WriteString("\n# Exported from go-recipe\n")
+	if names := parsePlaceholders(command.Command); len(names) > 0 {
+		fmt.Fprintf(&sb, "# Fill in the placeholders before running: %s\n", strings.Join(names, ", "))
+	}
+	sb.WriteString("\n")
+
+	switch {
+	case setup.createdDir != "":
+		fmt.Fprintf(&sb, "mkdir -p %s && cd %s || exit\n", shellQuote(setup.createdDir), shellQuote(setup.createdDir))
+	case strings.EqualFold(strings.TrimSpace(command.WorkingDirMode), "home"):
+		sb.WriteString("cd \"$HOME\" || exit\n")
+	case setup.dir != "":
+		fmt.Fprintf(&sb, "cd %s || exit\n", shellQuote(setup.dir))
+	}
+
+	for _, entry := range setup.env {
+		key, value, _ := strings.Cut(entry, "=")
+		fmt.Fprintf(&sb, "export %s=%s\n", key, shellQuote(value))
+	}
+
+	line := command.Command
+	if !viaShell {
+		quoted := make([]string, len(setup.argv))
+		for i, arg := range setup.argv {
+			quoted[i] = shellQuote(arg)
+		}
+		line = strings.Join(quoted, " ")
+	}
+	if strings.TrimSpace(command.StdinFile) != "" {
+		path, _ := expandDirPlaceholders(command.StdinFile)
+		line = fmt.Sprintf("{ %s\n} < %s", line, shellQuote(path))
+	}
+
+	pre := strings.TrimSpace(command.PreCommand)
+	post := strings.TrimSpace(command.PostCommand)
+	if command.Interactive {
+		pre, post = "", ""
+	}
+	if pre != "" {
+		fmt.Fprintf(&sb, "\n# Pre-command: the command is skipped when it fails\n%s || exit\n", pre)
+	}
+	if post == "" {
+		fmt.Fprintf(&sb, "\n%s\n", line)
+		return sb.String(), nil
+	}
+	fmt.Fprintf(&sb, "\n%s\nstatus=$?\n", line)
+	fmt.Fprintf(&sb, "\n# Post-command\n%s\nexit $status\n", post)
+	return sb.String(), nil
+}
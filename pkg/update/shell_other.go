@@ -0,0 +1,11 @@
+//go:build !windows
+
+package update
+
+import "os/exec"
+
+// setShellCmdLine is only needed for cmd.exe on Windows
+func setShellCmdLine(cmd *exec.Cmd, argv []string) {}
+
+// setCmdLine is only needed for cmd.exe on Windows
+func setCmdLine(cmd *exec.Cmd, line string) {}
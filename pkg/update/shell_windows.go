@@ -0,0 +1,26 @@
+//go:build windows
+
+package update
+
+import (
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// setShellCmdLine hands the script to cmd.exe verbatim. cmd doesn't parse its
+// command line with the argv quoting rules exec uses, so with /S the script is
+// wrapped in one pair of quotes that cmd strips again.
+func setShellCmdLine(cmd *exec.Cmd, argv []string) {
+	if shellName(argv[0]) != "cmd" {
+		return
+	}
+	script := argv[len(argv)-1]
+	line := `"` + argv[0] + `" ` + strings.Join(argv[1:len(argv)-1], " ") + ` "` + script + `"`
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: line}
+}
+
+// setCmdLine passes line to the process as its command line as is
+func setCmdLine(cmd *exec.Cmd, line string) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: line}
+}
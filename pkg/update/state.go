@@ -0,0 +1,35 @@
+package update
+
+import (
+	"slices"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// SessionState returns the list view state worth restoring next time
+func SessionState(m model.Model) model.SessionState {
+	return model.SessionState{
+		ActiveCategory:  m.ActiveCategory,
+		FilterText:      m.FilterText,
+		SortMode:        m.SortMode.String(),
+		GroupByCategory: m.GroupByCategory,
+		DetailedList:    m.DetailedList,
+	}
+}
+
+// RestoreSessionState applies a saved list view state to a freshly loaded
+// model. A category that no longer exists falls back to "All" and an unknown
+// sort mode to config order.
+func RestoreSessionState(m model.Model, state model.SessionState) model.Model {
+	m.ActiveCategory = "All"
+	if slices.Contains(m.Categories, state.ActiveCategory) {
+		m.ActiveCategory = state.ActiveCategory
+	}
+	m.FilterText = state.FilterText
+	m.SortMode, _ = model.ParseSortMode(state.SortMode)
+	m.GroupByCategory = state.GroupByCategory
+	m.DetailedList = state.DetailedList
+	m.SelectedIndex = 0
+	applyFilter(&m)
+	return m
+}
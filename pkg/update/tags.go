@@ -0,0 +1,66 @@
+package update
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// hasTags reports whether the command has any (or, with all set, every) one
+// of the tags. No tags matches every command.
+func hasTags(command model.Command, tags []string, all bool) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		found := slices.Contains(command.Tags, tag)
+		if found && !all {
+			return true
+		}
+		if !found && all {
+			return false
+		}
+	}
+	return all
+}
+
+// handleTagPickerMode toggles tag filters; the list updates as tags change
+func handleTagPickerMode(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "enter", "t":
+		m.CurrentMode = model.ModeNormal
+		m.TagOptions = nil
+		return m, nil
+	case "up", "k":
+		if m.TagIndex > 0 {
+			m.TagIndex--
+		}
+		return m, nil
+	case "down", "j":
+		if m.TagIndex < len(m.TagOptions)-1 {
+			m.TagIndex++
+		}
+		return m, nil
+	case " ":
+		if m.TagIndex >= len(m.TagOptions) {
+			return m, nil
+		}
+		tag := m.TagOptions[m.TagIndex]
+		if i := slices.Index(m.ActiveTags, tag); i >= 0 {
+			m.ActiveTags = slices.Delete(slices.Clone(m.ActiveTags), i, i+1)
+		} else {
+			m.ActiveTags = append(slices.Clone(m.ActiveTags), tag)
+		}
+	case "a":
+		// Switch between matching any and all of the selected tags
+		m.MatchAllTags = !m.MatchAllTags
+	case "x":
+		m.ActiveTags = nil
+	default:
+		return m, nil
+	}
+	refreshVisible(&m)
+	return m, nil
+}
@@ -0,0 +1,104 @@
+package update
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// startBackground runs the command in the background, writing its output to
+// a new log file, and tracks it as a task. The returned command blocks until
+// the run finishes and reports it with BackgroundDoneMsg; it is nil if the log
+// couldn't be created.
+func startBackground(command model.Command, m model.Model) (model.Model, tea.Cmd) {
+	logPath, err := createBackgroundLogFile(command)
+	if err != nil {
+		m.Error = fmt.Sprintf("Failed to create background log: %v", err)
+		return m, nil
+	}
+	id := 1
+	if len(m.Tasks) > 0 {
+		id = m.Tasks[len(m.Tasks)-1].ID + 1
+	}
+	task := model.BackgroundTask{
+		ID:      id,
+		Name:    command.Name,
+		LogPath: logPath,
+		Started: time.Now(),
+	}
+	m.Tasks = append(m.Tasks, task)
+
+	settings := m.Settings
+	return m, func() tea.Msg {
+		f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return BackgroundDoneMsg{TaskID: task.ID, Result: Result{Command: command, Error: err, StartTime: task.Started, EndTime: time.Now(), ExitCode: -1}}
+		}
+		defer f.Close()
+		var result Result
+		if !hasRedaction(command, settings) {
+			result = ExecuteCommandStreaming(command, f)
+		} else {
+			// Mask secrets before they are written to the log
+			w := &redactWriter{w: f, redact: func(s string) string { return RedactOutput(s, command, settings) }}
+			result = ExecuteCommandStreaming(command, w)
+			_ = w.Flush()
+		}
+		if !settings.DisableNotifications {
+			notifyFinished(command, result)
+		}
+		return BackgroundDoneMsg{TaskID: task.ID, Result: result}
+	}
+}
+
+// handleBackgroundDone marks the task as finished
+func handleBackgroundDone(msg BackgroundDoneMsg, m model.Model) (model.Model, tea.Cmd) {
+	for i := range m.Tasks {
+		if m.Tasks[i].ID == msg.TaskID {
+			m.Tasks[i].Done = true
+			m.Tasks[i].ExitCode = msg.Result.ExitCode
+			m.Tasks[i].Finished = msg.Result.EndTime
+		}
+	}
+	if err := RecordHistory(msg.Result); err != nil {
+		m.Error = fmt.Sprintf("Failed to record the run in the history: %v", err)
+	}
+	recordExit(&m, msg.Result)
+	return m, nil
+}
+
+// handleTasksKeyPress navigates the task list and opens the selected task's log
+func handleTasksKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "T":
+		m.ShowTasks = false
+	case "up", "k":
+		if m.TaskIndex > 0 {
+			m.TaskIndex--
+		}
+	case "down", "j":
+		if m.TaskIndex < len(m.Tasks)-1 {
+			m.TaskIndex++
+		}
+	case "c":
+		// Clear finished tasks from the list
+		var running []model.BackgroundTask
+		for _, task := range m.Tasks {
+			if !task.Done {
+				running = append(running, task)
+			}
+		}
+		m.Tasks = running
+		m.TaskIndex = 0
+	case "enter":
+		if m.TaskIndex < len(m.Tasks) {
+			task := m.Tasks[m.TaskIndex]
+			return viewLog(model.LogFile{Name: task.Name, Path: task.LogPath}, m)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,151 @@
+package update
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// linuxTerminals are the terminal emulators tried on Linux, in order, with
+// the flags that make them run a command
+var linuxTerminals = []struct {
+	name string
+	args []string
+}{
+	{"x-terminal-emulator", []string{"-e"}},
+	{"gnome-terminal", []string{"--"}},
+	{"konsole", []string{"-e"}},
+	{"xterm", []string{"-e"}},
+}
+
+// openInTerminal starts an interactive command in a new terminal window and
+// leaves the TUI running. The command is written to a temporary script the
+// new terminal runs, so it reaches the shell verbatim whatever quotes, $VARs
+// or backticks it contains.
+func openInTerminal(command model.Command) error {
+	script, err := writeTerminalScript(command, runtime.GOOS)
+	if err != nil {
+		return err
+	}
+	cmd, err := terminalCmd(runtime.GOOS, script, exec.LookPath)
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		os.Remove(script)
+		return err
+	}
+	// The terminal runs on its own; reap it when it exits
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// terminalCmd returns the command that opens a new terminal window on goos
+// running script: Terminal.app through osascript on macOS, cmd /C start on
+// Windows (see windowsStartLine) and the first of linuxTerminals found by lookPath elsewhere
+func terminalCmd(goos, script string, lookPath func(string) (string, error)) (*exec.Cmd, error) {
+	switch goos {
+	case "darwin":
+		run := appleScriptString("bash " + shellQuote(script))
+		return exec.Command("osascript",
+			"-e", fmt.Sprintf(`tell application "Terminal" to do script %s`, run),
+			"-e", `tell application "Terminal" to activate`), nil
+	case "windows":
+		cmd := exec.Command("cmd", "/C", "start", "", "cmd", "/C", script)
+		setCmdLine(cmd, windowsStartLine(script))
+		return cmd, nil
+	}
+	var tried []string
+	for _, term := range linuxTerminals {
+		path, err := lookPath(term.name)
+		if err != nil {
+			tried = append(tried, term.name)
+			continue
+		}
+		args := append(append([]string{}, term.args...), "bash", script)
+		return exec.Command(path, args...), nil
+	}
+	return nil, fmt.Errorf("no terminal emulator found (tried %s)", strings.Join(tried, ", "))
+}
+
+// windowsStartLine is the cmd.exe command line opening script in a new
+// console window. start takes its first quoted argument as the window title,
+// so an empty title goes first and the script path is quoted once, as cmd
+// expects rather than with the argv quoting exec uses.
+func windowsStartLine(script string) string {
+	return `cmd /C start "" cmd /C "` + script + `"`
+}
+
+// writeTerminalScript writes the script a new terminal runs for the command
+// on goos to a temporary file and returns its path. On Windows it is a batch
+// file; elsewhere it is the bash script ShellScript exports, removing itself
+// once started.
+func writeTerminalScript(command model.Command, goos string) (string, error) {
+	var script, pattern string
+	var err error
+	if goos == "windows" {
+		script, err = batchScript(command)
+		pattern = "go-recipe-*.cmd"
+	} else {
+		script, err = ShellScript(command)
+		script = strings.Replace(script, "\n", "\nrm -f -- \"$0\"\n", 1)
+		pattern = "go-recipe-*.sh"
+	}
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create the script: %w", err)
+	}
+	_, err = f.WriteString(script)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write the script: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// batchScript turns a command into a Windows batch file running it in its
+// working directory, with its environment and stdin file. Percent signs are
+// doubled so the batch file runs the command as written instead of expanding
+// %1 or %VAR% in it, and the file deletes itself once done; (goto) first
+// leaves the batch context so cmd doesn't go on reading the deleted file.
+func batchScript(command model.Command) (string, error) {
+	setup, err := resolveRun(command, true)
+	if err != nil {
+		return "", err
+	}
+
+	const remove = `(goto) 2>nul & del "%~f0"`
+	escape := strings.NewReplacer("%", "%%").Replace
+	var sb strings.Builder
+	sb.WriteString("@echo off\r\n")
+	dir := setup.dir
+	if setup.createdDir != "" {
+		dir = setup.createdDir
+		fmt.Fprintf(&sb, "md \"%s\" 2>nul\r\n", escape(dir))
+	}
+	if dir != "" {
+		fmt.Fprintf(&sb, "cd /d \"%s\" || (%s)\r\n", escape(dir), remove)
+	}
+	for _, entry := range setup.env {
+		fmt.Fprintf(&sb, "set \"%s\"\r\n", escape(entry))
+	}
+	line := command.Command
+	if strings.TrimSpace(command.StdinFile) != "" {
+		path, _ := expandDirPlaceholders(command.StdinFile)
+		line = fmt.Sprintf("%s < \"%s\"", line, path)
+	}
+	sb.WriteString(escape(line) + "\r\n")
+	sb.WriteString(remove + "\r\n")
+	return sb.String(), nil
+}
@@ -0,0 +1,134 @@
+package update
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+func TestTerminalScriptRunsCommandsVerbatim(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("bash scripts")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	t.Setenv("GREETING", "hello")
+
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{"double quotes", `echo "say \"hi\""`, `say "hi"`},
+		{"single quotes", `echo 'it''s' "it's"`, `its it's`},
+		{"variables expand once", `echo "$GREETING" '$GREETING'`, `hello $GREETING`},
+		{"backticks", "echo `echo nested` '`echo not run`'", "nested `echo not run`"},
+		{"backslashes", `printf '%s\n' 'C:\temp' "a\\b"`, `C:\temp a\b`},
+		{"AppleScript quoting", `echo '" & do shell script "date'`, `" & do shell script "date`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command := model.Command{Name: tt.name, Command: tt.command, Interactive: true}
+			script, err := writeTerminalScript(command, runtime.GOOS)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { os.Remove(script) })
+
+			out, err := exec.Command("bash", script).CombinedOutput()
+			if err != nil {
+				t.Fatalf("script failed: %v\n%s", err, out)
+			}
+			if got := strings.Join(strings.Fields(string(out)), " "); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			// The script removes itself once started
+			if _, err := os.Stat(script); !os.IsNotExist(err) {
+				t.Errorf("script %s is left behind", script)
+			}
+		})
+	}
+}
+
+func TestTerminalCmd(t *testing.T) {
+	script := "/tmp/go recipe's script.sh"
+	found := func(names ...string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			if slices.Contains(names, name) {
+				return "/usr/bin/" + name, nil
+			}
+			return "", exec.ErrNotFound
+		}
+	}
+
+	tests := []struct {
+		name     string
+		goos     string
+		lookPath func(string) (string, error)
+		want     []string
+	}{
+		{"macOS", "darwin", found(), []string{"osascript",
+			"-e", `tell application "Terminal" to do script "bash '/tmp/go recipe'\\''s script.sh'"`,
+			"-e", `tell application "Terminal" to activate`}},
+		{"Windows", "windows", found(), []string{"cmd", "/C", "start", "", "cmd", "/C", script}},
+		{"Debian alternatives first", "linux", found("xterm", "x-terminal-emulator"), []string{"/usr/bin/x-terminal-emulator", "-e", "bash", script}},
+		{"GNOME", "linux", found("gnome-terminal", "xterm"), []string{"/usr/bin/gnome-terminal", "--", "bash", script}},
+		{"KDE", "linux", found("konsole"), []string{"/usr/bin/konsole", "-e", "bash", script}},
+		{"xterm last", "linux", found("xterm"), []string{"/usr/bin/xterm", "-e", "bash", script}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := terminalCmd(tt.goos, script, tt.lookPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(cmd.Args, tt.want) {
+				t.Errorf("Args = %q, want %q", cmd.Args, tt.want)
+			}
+		})
+	}
+
+	// An empty title comes first, or start would take the quoted script path
+	// as the window title
+	if got, want := windowsStartLine(`C:\Temp\go recipe.cmd`), `cmd /C start "" cmd /C "C:\Temp\go recipe.cmd"`; got != want {
+		t.Errorf("windowsStartLine() = %s, want %s", got, want)
+	}
+
+	if _, err := terminalCmd("linux", script, found()); err == nil || !strings.Contains(err.Error(), "xterm") {
+		t.Errorf("no terminal installed: err = %v, want the terminals tried", err)
+	}
+}
+
+func TestBatchScript(t *testing.T) {
+	dir := t.TempDir()
+	command := model.Command{
+		Command:        `echo "%PATH%" & dir`,
+		WorkingDirMode: "absolute",
+		WorkingDirPath: dir,
+		Env:            []string{"STAGE=100%"},
+	}
+	script, err := batchScript(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Percent signs are doubled so the batch file doesn't expand them itself,
+	// and the script deletes itself whether or not the command ran
+	want := "@echo off\r\n" +
+		"cd /d \"" + dir + "\" || ((goto) 2>nul & del \"%~f0\")\r\n" +
+		"set \"STAGE=100%%\"\r\n" +
+		"echo \"%%PATH%%\" & dir\r\n" +
+		"(goto) 2>nul & del \"%~f0\"\r\n"
+	if script != want {
+		t.Errorf("batchScript() = %q, want %q", script, want)
+	}
+
+	if _, err := batchScript(model.Command{Command: "x", WorkingDirMode: "absolute"}); err == nil {
+		t.Error("batchScript() accepted a command without its working directory")
+	}
+}
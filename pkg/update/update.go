@@ -1,13 +1,18 @@
 package update
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Tomlord1122/go-recipe/pkg/config"
 	"github.com/Tomlord1122/go-recipe/pkg/model"
@@ -21,26 +26,89 @@ type (
 	CommandResultMsg  struct{ Result Result }
 	StreamPollMsg     struct{}
 	SpinnerTickMsg    struct{}
+	PagerClosedMsg    struct{ Error error }
+	ConfigEditedMsg   struct{ Error error }
+	WatchTickMsg      struct{ ID int }
+	WatchResultMsg    struct {
+		ID     int
+		Result Result
+	}
+	ClearMessageMsg   struct{ Message string }
+	ElapsedTickMsg    struct{}
+	BackgroundDoneMsg struct {
+		TaskID int
+		Result Result
+	}
 )
 
+// flashDuration is how long transient confirmations stay on screen
+const flashDuration = 2 * time.Second
+
+// flash shows a transient confirmation that clears itself unless another
+// message replaced it in the meantime
+func flash(m model.Model, message string) (model.Model, tea.Cmd) {
+	m.Info = message
+	return m, tea.Tick(flashDuration, func(time.Time) tea.Msg { return ClearMessageMsg{Message: message} })
+}
+
 // Update handles state transitions based on messages
 func Update(msg tea.Msg, m model.Model) (model.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return handleKeyPress(msg, m)
+	case tea.MouseMsg:
+		return handleMouse(msg, m)
 	case tea.WindowSizeMsg:
 		m.Width = msg.Width
 		m.Height = msg.Height
+		scrollListToSelection(&m)
+		// Let an interactive command in a pseudo-terminal reflow to the new size
+		resizePTY(m)
 		return m, nil
 	case ErrorMsg:
 		m.Error = msg.Error.Error()
 		return m, nil
 	case ExecuteCommandMsg:
+		if names := parsePlaceholders(msg.Command.Command); len(names) > 0 {
+			return promptPlaceholders(msg.Command, names, m)
+		}
 		return executeCommand(msg.Command, m)
 	case CommandResultMsg:
 		return handleCommandResult(msg.Result, m)
 	case StreamPollMsg:
 		return handleStreamPoll(m)
+	case WatchTickMsg:
+		if m.Watching && msg.ID == m.WatchID && m.ExecutingCommand != nil {
+			return runWatch(*m.ExecutingCommand, m)
+		}
+		return m, nil
+	case WatchResultMsg:
+		return handleWatchResult(msg, m)
+	case ElapsedTickMsg:
+		// Refresh the elapsed time once per second until the command finishes
+		if m.Executing && m.Spinning {
+			m.ExecutionElapsed = time.Since(m.ExecutionStart).Truncate(time.Second)
+			return m, tea.Tick(time.Second, func(time.Time) tea.Msg { return ElapsedTickMsg{} })
+		}
+		return m, nil
+	case BackgroundDoneMsg:
+		return handleBackgroundDone(msg, m)
+	case ClearMessageMsg:
+		if m.Info == msg.Message {
+			m.Info = ""
+		}
+		return m, nil
+	case PagerClosedMsg:
+		// Return to the list once the pager is closed
+		m.Executing = false
+		m.ExecutingCommand = nil
+		removeTempLog(&m)
+		if msg.Error != nil {
+			m.Error = fmt.Sprintf("Pager exited with error: %v", msg.Error)
+		}
+		return m, nil
+	case ConfigEditedMsg:
+		return handleConfigEdited(msg, m)
 	case SpinnerTickMsg:
 		if m.Executing {
 			m.ExecutingAnimIndex = (m.ExecutingAnimIndex + 1) % 4
@@ -54,23 +122,64 @@ func Update(msg tea.Msg, m model.Model) (model.Model, tea.Cmd) {
 
 // handleKeyPress processes keyboard input
 func handleKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
-	// Clear any previous error messages
+	// Clear any previous error and status messages
 	m.Error = ""
+	m.Info = ""
 
 	// Check mode-specific handling
 	switch m.CurrentMode {
 	case model.ModeFilterInput:
 		return handleFilterInputMode(msg, m)
+	case model.ModePinSlot:
+		return handlePinSlotMode(msg, m)
+	case model.ModeConfirm:
+		return handleConfirmMode(msg, m)
+	case model.ModeWatchInterval:
+		return handleWatchIntervalMode(msg, m)
+	case model.ModePalette:
+		return handlePaletteMode(msg, m)
+	case model.ModePlaceholder:
+		return handlePlaceholderMode(msg, m)
+	case model.ModeTagPicker:
+		return handleTagPickerMode(msg, m)
+	case model.ModeConfirmQuit:
+		return handleConfirmQuitMode(msg, m)
 	}
 
 	// Form field editing takes priority over all other key handlers
 	if m.ShowForm && m.EditingFormField {
-		return handleFormFieldEdit(msg, m)
+		var cmd tea.Cmd
+		m, cmd = handleFormFieldEdit(msg, m)
+		updateFormPreview(&m)
+		return m, cmd
+	}
+
+	// So does typing a new category name
+	if m.ShowCategories && m.RenamingCategory {
+		return handleCategoryRename(msg, m)
+	}
+
+	// And typing into an interactive command running in a pseudo-terminal
+	if m.Executing && m.PTY != nil {
+		return handlePTYKeyPress(msg, m)
 	}
 
 	// Handle global keys
 	switch msg.String() {
-	case "ctrl+c", "q":
+	case "ctrl+c":
+		// Stop a running foreground command rather than quitting
+		if m.Executing && m.ExecutionCancel != nil {
+			return cancelExecution(m), nil
+		}
+		removeTempLog(&m)
+		return m, tea.Quit
+	case "q":
+		// Quitting would leave a running foreground command behind, so ask first
+		if m.Executing && m.ExecutionCancel != nil {
+			m.CurrentMode = model.ModeConfirmQuit
+			return m, nil
+		}
+		removeTempLog(&m)
 		return m, tea.Quit
 	case "h":
 		// Only toggle help if not in form mode
@@ -80,6 +189,15 @@ func handleKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 		}
 	}
 
+	// The command palette is reachable from the list and execution views
+	if msg.String() == "ctrl+p" && !m.ShowForm && !m.ShowHelp {
+		m.CurrentMode = model.ModePalette
+		m.PaletteQuery = ""
+		m.PaletteIndex = 0
+		m.PaletteResults = paletteResults(m.AllCommands, "")
+		return m, nil
+	}
+
 	// Handle keys based on current view
 	if m.Executing {
 		return handleExecutionKeyPress(msg, m)
@@ -90,27 +208,83 @@ func handleKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 	}
 
 	if m.ShowForm {
-		return handleFormKeyPress(msg, m)
+		var cmd tea.Cmd
+		m, cmd = handleFormKeyPress(msg, m)
+		updateFormPreview(&m)
+		return m, cmd
+	}
+
+	if m.ShowLogs {
+		return handleLogsKeyPress(msg, m)
+	}
+
+	if m.ShowTasks {
+		return handleTasksKeyPress(msg, m)
 	}
 
-	return handleMainKeyPress(msg, m)
+	if m.ShowCategories {
+		return handleCategoriesKeyPress(msg, m)
+	}
+
+	var cmd tea.Cmd
+	m, cmd = handleMainKeyPress(msg, m)
+	scrollListToSelection(&m)
+	return m, cmd
 }
 
 // handleMainKeyPress processes key presses in the main view
 func handleMainKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	// Unprotecting needs two L presses in a row
+	unprotectID := m.UnprotectID
+	m.UnprotectID = ""
+
+	// Protected commands can't be edited or deleted
+	switch msg.String() {
+	case "e", "E", "d":
+		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) && m.VisibleCommands[m.SelectedIndex].Protected {
+			m.Error = fmt.Sprintf("%q is protected. Press L twice to unprotect it", m.VisibleCommands[m.SelectedIndex].Name)
+			return m, nil
+		}
+	}
+
 	switch msg.String() {
+	case "esc":
+		// Dismiss the warning shown until the user has seen it
+		m.Warning = ""
 	case "up", "k":
 		if m.SelectedIndex > 0 {
 			m.SelectedIndex--
+		} else if m.Settings.WrapNavigation && len(m.VisibleCommands) > 0 {
+			m.SelectedIndex = len(m.VisibleCommands) - 1
 		}
 	case "down", "j":
 		if m.SelectedIndex < len(m.VisibleCommands)-1 {
 			m.SelectedIndex++
+		} else if m.Settings.WrapNavigation {
+			m.SelectedIndex = 0
+		}
+	case "g", "home":
+		m.SelectedIndex = 0
+	case "G", "end":
+		if len(m.VisibleCommands) > 0 {
+			m.SelectedIndex = len(m.VisibleCommands) - 1
 		}
+	case "pgup", "pgdown":
+		pageList(&m, msg.String() == "pgdown")
 	case "enter":
 		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) {
+			return requestExecute(m.VisibleCommands[m.SelectedIndex], m)
+		}
+	case "!":
+		// Quick run: execute without the confirm_before_run prompt; commands
+		// marked Confirm still ask
+		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) {
+			command := m.VisibleCommands[m.SelectedIndex]
+			if command.Confirm {
+				return requestExecute(command, m)
+			}
 			return m, func() tea.Msg {
-				return ExecuteCommandMsg{Command: m.VisibleCommands[m.SelectedIndex]}
+				return ExecuteCommandMsg{Command: command}
 			}
 		}
 	case "E":
@@ -121,6 +295,7 @@ func handleMainKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 			m.ActiveFormField = model.FieldCommand
 			m.EditingFormField = true
 			m.FormInputBuffer = m.FormCommand.Command
+			m.FormCursor = utf8.RuneCountInString(m.FormInputBuffer)
 			return m, nil
 		}
 	case "n":
@@ -137,40 +312,82 @@ func handleMainKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 			m.ShowForm = true
 			m.FormCommand = m.VisibleCommands[m.SelectedIndex]
 		}
+	case "D":
+		// Duplicate: open the form with a copy of the selected command, saved as new
+		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) {
+			original := m.VisibleCommands[m.SelectedIndex]
+			dup := original
+			dup.ID = ""
+			dup.Name = "Copy of " + original.Name
+			dup.LastRun = time.Time{}
+			dup.RunCount = 0
+			dup.LastExit = nil
+			dup.Protected = false
+			dup.Favorite = false
+			dup.Tags = append([]string{}, original.Tags...)
+			dup.Aliases = nil // aliases must stay unique
+			dup.Env = append([]string(nil), original.Env...)
+			dup.Redact = append([]string(nil), original.Redact...)
+			m.ShowForm = true
+			m.FormCommand = dup
+			m.ActiveFormField = model.FieldName
+		}
 	case "d":
 		// Delete selected command
 		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) {
 			cmdToDelete := m.VisibleCommands[m.SelectedIndex]
-			// Remove from all commands
+			// Remove from all commands, remembering where it was for undo
 			var newCommands []model.Command
-			for _, cmd := range m.AllCommands {
+			for i, cmd := range m.AllCommands {
 				if cmd.ID != cmdToDelete.ID {
 					newCommands = append(newCommands, cmd)
+				} else {
+					m.DeletedIndex = i
 				}
 			}
 			m.AllCommands = newCommands
+			m.DeletedCommand = &cmdToDelete
 
 			// Apply filter to get updated visible commands
-			m.VisibleCommands = filterCommands(m)
-
-			// Adjust selected index if needed
-			if m.SelectedIndex >= len(m.VisibleCommands) {
-				m.SelectedIndex = len(m.VisibleCommands) - 1
-			}
-			if m.SelectedIndex < 0 {
-				m.SelectedIndex = 0
-			}
+			applyFilter(&m)
 
 			// Save updated commands
-			if err := config.SaveConfig(m.AllCommands); err != nil {
-				m.Error = fmt.Sprintf("Failed to save config: %v", err)
+			saveCommands(&m)
+			if !m.UnsavedChanges {
+				m.Info = fmt.Sprintf("Deleted %q (u to undo)", cmdToDelete.Name)
+			}
+		}
+	case "u":
+		// Undo the last delete
+		if m.DeletedCommand == nil {
+			m.Info = "Nothing to undo"
+			return m, nil
+		}
+		restored := *m.DeletedCommand
+		idx := min(m.DeletedIndex, len(m.AllCommands))
+		m.AllCommands = append(m.AllCommands[:idx], append([]model.Command{restored}, m.AllCommands[idx:]...)...)
+		m.DeletedCommand = nil
+		m.Categories = config.GetCategories(m.AllCommands, m.Settings.CategoryOrder)
+		applyFilter(&m)
+		for i, cmd := range m.VisibleCommands {
+			if cmd.ID == restored.ID {
+				m.SelectedIndex = i
 			}
 		}
+		saveCommands(&m)
+		if !m.UnsavedChanges {
+			m.Info = fmt.Sprintf("Restored %q", restored.Name)
+		}
 	case "c":
 		// Cycle through categories
+		// No active category means "All", so the first press moves past it
+		active := m.ActiveCategory
+		if active == "" {
+			active = "All"
+		}
 		found := false
 		for i, category := range m.Categories {
-			if category == m.ActiveCategory {
+			if category == active {
 				if i < len(m.Categories)-1 {
 					m.ActiveCategory = m.Categories[i+1]
 				} else {
@@ -183,7 +400,114 @@ func handleMainKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 		if !found && len(m.Categories) > 0 {
 			m.ActiveCategory = m.Categories[0]
 		}
-		m.VisibleCommands = filterCommands(m)
+		applyFilter(&m)
+	case "L":
+		// Toggle protection of the selected command
+		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) {
+			selected := m.VisibleCommands[m.SelectedIndex]
+			if selected.Protected && unprotectID != selected.ID {
+				m.UnprotectID = selected.ID
+				m.Info = fmt.Sprintf("Press L again to unprotect %q", selected.Name)
+				return m, nil
+			}
+			for i, cmd := range m.AllCommands {
+				if cmd.ID == selected.ID {
+					m.AllCommands[i].Protected = !cmd.Protected
+					break
+				}
+			}
+			applyFilter(&m)
+			saveCommands(&m)
+		}
+	case "*":
+		// Toggle the selected command's favorite mark
+		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) {
+			selected := m.VisibleCommands[m.SelectedIndex]
+			for i, cmd := range m.AllCommands {
+				if cmd.ID == selected.ID {
+					m.AllCommands[i].Favorite = !cmd.Favorite
+					break
+				}
+			}
+			// Favorites comes and goes with the first and last favorite
+			m.Categories = config.GetCategories(m.AllCommands, m.Settings.CategoryOrder)
+			if !slices.Contains(m.Categories, m.ActiveCategory) {
+				m.ActiveCategory = "All"
+			}
+			refreshVisible(&m)
+			saveCommands(&m)
+		}
+	case "s":
+		// Cycle the sort mode, keeping the selected command selected
+		m.SortMode = (m.SortMode + 1) % model.SortModeCount
+		refreshVisible(&m)
+	case "S":
+		// Toggle grouping the "All" list under category headers
+		m.GroupByCategory = !m.GroupByCategory
+		refreshVisible(&m)
+	case "i":
+		// Switch between compact rows and rows with their description
+		m.DetailedList = !m.DetailedList
+		scrollListToSelection(&m)
+	case " ":
+		// Mark or unmark the selected command for a batch run
+		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) {
+			id := m.VisibleCommands[m.SelectedIndex].ID
+			if m.Marked[id] {
+				delete(m.Marked, id)
+			} else {
+				if m.Marked == nil {
+					m.Marked = map[string]bool{}
+				}
+				m.Marked[id] = true
+			}
+		}
+	case "R":
+		// Run the marked commands one after another
+		return startBatch(m)
+	case "t":
+		// Pick tags to filter the list by
+		m.CurrentMode = model.ModeTagPicker
+		m.TagOptions = config.GetTags(m.AllCommands)
+		m.TagIndex = 0
+	case "o":
+		// Reopen the stored last result of the selected command
+		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) {
+			return openLastResult(m.VisibleCommands[m.SelectedIndex], m)
+		}
+	case "O":
+		// Edit the commands file by hand, reloading it afterwards
+		return editConfig(m)
+	case "V":
+		// Dry run: show what would run, asking for placeholders first
+		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) {
+			command := m.VisibleCommands[m.SelectedIndex]
+			if names := parsePlaceholders(command.Command); len(names) > 0 {
+				m, cmd := promptPlaceholders(command, names, m)
+				m.PlaceholderDryRun = true
+				return m, cmd
+			}
+			return showDryRun(command, m)
+		}
+	case "C":
+		// Manage categories: rename, merge and delete
+		m.ShowCategories = true
+		m.CategoryIndex = 0
+	case "T":
+		// Show the background tasks started in this session
+		m.ShowTasks = true
+		m.TaskIndex = 0
+	case "l":
+		// Browse background run logs
+		return openLogs(m)
+	case "ctrl+s":
+		// Retry writing commands that failed to save
+		if m.UnsavedChanges {
+			saveCommands(&m)
+			if !m.UnsavedChanges {
+				m.Info = "Changes saved to disk"
+			}
+		}
 	case "b":
 		// Toggle background mode
 		m.RunInBackground = !m.RunInBackground
@@ -192,11 +516,152 @@ func handleMainKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 		m.CurrentMode = model.ModeFilterInput
 		m.InputBuffer = m.FilterText // Start with current filter
 		return m, nil
+	case "w":
+		// Watch: prompt for an interval, then re-run the command periodically
+		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) {
+			command := m.VisibleCommands[m.SelectedIndex]
+			if command.Interactive {
+				m.Error = "Interactive commands can't be watched"
+				return m, nil
+			}
+			// Placeholders are asked for first, then the interval
+			if names := parsePlaceholders(command.Command); len(names) > 0 {
+				m, cmd := promptPlaceholders(command, names, m)
+				m.PlaceholderWatch = true
+				return m, cmd
+			}
+			m = promptWatchInterval(command, m)
+		}
+		return m, nil
+	case "p":
+		// Pin the selected command to a quick-launch slot
+		if len(m.VisibleCommands) > 0 && m.SelectedIndex < len(m.VisibleCommands) {
+			m.CurrentMode = model.ModePinSlot
+		}
+		return m, nil
+	case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+		// Execute the command pinned to the slot
+		slot := int(msg.String()[len("alt+")] - '0')
+		id, ok := m.Settings.PinnedSlots[slot]
+		if !ok {
+			m.Error = fmt.Sprintf("Slot %d is empty. Press p to pin the selected command", slot)
+			return m, nil
+		}
+		for _, cmd := range m.AllCommands {
+			if cmd.ID == id {
+				return requestExecute(cmd, m)
+			}
+		}
+		m.Error = fmt.Sprintf("The command pinned to slot %d no longer exists", slot)
+	}
+
+	return m, nil
+}
+
+// requestExecute executes the command, asking for confirmation first when enabled
+func requestExecute(command model.Command, m model.Model) (model.Model, tea.Cmd) {
+	if command.AsksBeforeRun(m.Settings) {
+		m.CurrentMode = model.ModeConfirm
+		m.ConfirmCommand = &command
+		return m, nil
+	}
+	return m, func() tea.Msg {
+		return ExecuteCommandMsg{Command: command}
+	}
+}
+
+// handleConfirmMode runs the pending command or batch on y and cancels on n/esc
+func handleConfirmMode(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.CurrentMode = model.ModeNormal
+		if m.ConfirmBatch {
+			m.ConfirmBatch = false
+			return runBatch(markedCommands(m), m)
+		}
+		command := *m.ConfirmCommand
+		m.ConfirmCommand = nil
+		return m, func() tea.Msg {
+			return ExecuteCommandMsg{Command: command}
+		}
+	case "n", "N", "esc":
+		m.CurrentMode = model.ModeNormal
+		m.ConfirmCommand = nil
+		m.ConfirmBatch = false
+	}
+	return m, nil
+}
+
+// handleConfirmQuitMode answers the prompt shown when q is pressed while a
+// command runs: y kills the command and quits once it has exited
+func handleConfirmQuitMode(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.CurrentMode = model.ModeNormal
+		// The command may have finished while the prompt was open
+		if m.ExecutionCancel == nil {
+			removeTempLog(&m)
+			return m, tea.Quit
+		}
+		m = cancelExecution(m)
+		m.QuitAfterRun = true
+		m.Info = "Stopping the command before quitting..."
+	case "n", "N", "esc":
+		m.CurrentMode = model.ModeNormal
+	}
+	return m, nil
+}
+
+// handlePinSlotMode assigns the selected command to the quick-launch slot typed next
+func handlePinSlotMode(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	m.CurrentMode = model.ModeNormal
+	if len(m.VisibleCommands) == 0 || m.SelectedIndex >= len(m.VisibleCommands) {
+		return m, nil
+	}
+	selected := m.VisibleCommands[m.SelectedIndex]
+
+	key := msg.String()
+	switch {
+	case key == "esc":
+		return m, nil
+	case len(key) == 1 && key[0] >= '0' && key[0] <= '9':
+		// A command occupies at most one slot; 0 only unpins
+		if slot := m.Settings.SlotFor(selected.ID); slot != 0 {
+			delete(m.Settings.PinnedSlots, slot)
+		}
+		if key != "0" {
+			m.Settings.PinnedSlots[int(key[0]-'0')] = selected.ID
+		}
+	default:
+		m.Info = "Press 1-9 to pin, 0 to unpin"
+		return m, nil
 	}
 
+	if err := config.SaveSettings(m.Settings); err != nil {
+		m.Error = fmt.Sprintf("Failed to save settings: %v", err)
+	}
 	return m, nil
 }
 
+// outputVisibleLines returns how many output lines fit on screen, leaving
+// room for headers and footer
+func outputVisibleLines(height int) int {
+	visibleLines := height - 10
+	if visibleLines < 5 {
+		visibleLines = 5 // Minimum visible lines
+	}
+	return visibleLines
+}
+
+// maxOutputScroll returns the largest scroll position for the current output
+func maxOutputScroll(m model.Model) int {
+	maxScroll := strings.Count(m.ExecutionOutput, "\n") + 1 - outputVisibleLines(m.Height)
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	return maxScroll
+}
+
 // handleExecutionKeyPress processes key presses in the execution view
 func handleExecutionKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 	// Get the total number of lines in the output
@@ -204,10 +669,7 @@ func handleExecutionKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cm
 	totalLines := len(outputLines)
 
 	// Calculate visible lines based on screen height (leave room for headers and footer)
-	visibleLines := m.Height - 10
-	if visibleLines < 5 {
-		visibleLines = 5 // Minimum visible lines
-	}
+	visibleLines := outputVisibleLines(m.Height)
 
 	// Calculate maximum scroll position
 	maxScroll := totalLines - visibleLines
@@ -215,26 +677,50 @@ func handleExecutionKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cm
 		maxScroll = 0
 	}
 
+	if m.SelectionActive {
+		return handleSelectionKeyPress(msg, m, outputLines, visibleLines)
+	}
+
 	switch msg.String() {
 	case "esc", "q", "enter":
 		if m.ExecutionCancel != nil {
 			m.ExecutionCancel()
 		}
+		m.BatchQueue = nil
+		m.ViewingLastResult = false
+		m.ViewingDryRun = false
+		m.AlternateOutput = ""
 		m.Executing = false
 		m.ExecutingCommand = nil
+		stopWatching(&m)
+		m.SelectionActive = false
 		m.OutputScrollPosition = 0 // Reset scroll position when exiting
-		m.ExecutionLogPath = ""
-		m.ExecutionLogOffset = 0
+		m.ExecutionCancel = nil
+		stopTailing(&m)
+		removeTempLog(&m)
+	case "x":
+		// Kill the running command; its partial output stays visible
+		if m.ExecutionCancel != nil {
+			m = cancelExecution(m)
+		}
 	case "up", "k":
 		// Scroll up one line
 		if m.OutputScrollPosition > 0 {
 			m.OutputScrollPosition--
 		}
+		m.FollowOutput = false
 	case "down", "j":
 		// Scroll down one line
 		if m.OutputScrollPosition < maxScroll {
 			m.OutputScrollPosition++
 		}
+		m.FollowOutput = m.OutputScrollPosition >= maxScroll
+	case "F":
+		// Toggle following new output
+		m.FollowOutput = !m.FollowOutput
+		if m.FollowOutput {
+			m.OutputScrollPosition = maxScroll
+		}
 	case "pgup":
 		// Scroll up one page (visibleLines - 2 lines to maintain context)
 		pageSize := visibleLines - 2
@@ -245,6 +731,7 @@ func handleExecutionKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cm
 		if m.OutputScrollPosition < 0 {
 			m.OutputScrollPosition = 0
 		}
+		m.FollowOutput = false
 	case "pgdown":
 		// Scroll down one page (visibleLines - 2 lines to maintain context)
 		pageSize := visibleLines - 2
@@ -255,12 +742,107 @@ func handleExecutionKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cm
 		if m.OutputScrollPosition > maxScroll {
 			m.OutputScrollPosition = maxScroll
 		}
+		m.FollowOutput = m.OutputScrollPosition >= maxScroll
 	case "home":
 		// Scroll to the top
 		m.OutputScrollPosition = 0
+		m.FollowOutput = false
 	case "end":
-		// Scroll to the bottom
+		// Scroll to the bottom and keep following new output
 		m.OutputScrollPosition = maxScroll
+		m.FollowOutput = true
+	case "w":
+		// Stop watching but keep the last output on screen
+		stopWatching(&m)
+	case "y":
+		// Copy the raw output, never the styled render
+		if err := copyToClipboard(copyableOutput(m, m.Settings.CopyIncludeHeader)); err != nil {
+			m.Error = fmt.Sprintf("Failed to copy output: %v", err)
+			return m, nil
+		}
+		return flash(m, "Output copied to the clipboard")
+	case "s":
+		// Save the full output, not just the visible window
+		if m.ExecutingCommand == nil {
+			return m, nil
+		}
+		path, err := saveOutputFile(*m.ExecutingCommand, copyableOutput(m, true))
+		if err != nil {
+			m.Error = fmt.Sprintf("Failed to save output: %v", err)
+			return m, nil
+		}
+		m.Info = fmt.Sprintf("Output saved to %s", path)
+	case "e":
+		// Switch between combined and separated stdout/stderr of a finished run
+		if m.AlternateOutput == "" {
+			m.Info = "No stderr output to separate"
+			return m, nil
+		}
+		m.SeparateStderr = !m.SeparateStderr
+		m.ExecutionOutput, m.AlternateOutput = m.AlternateOutput, m.ExecutionOutput
+		m.OutputScrollPosition = min(m.OutputScrollPosition, maxOutputScroll(m))
+	case "v":
+		// Start selecting lines from the top of the visible window
+		m.SelectionActive = true
+		m.SelectionAnchor = m.OutputScrollPosition
+		m.SelectionCursor = m.OutputScrollPosition
+	}
+	return m, nil
+}
+
+// cancelExecution kills the running foreground command. The result message
+// that follows finishes the execution and keeps the output read so far.
+func cancelExecution(m model.Model) model.Model {
+	m.ExecutionCancel()
+	m.ExecutionCancel = nil
+	m.Info = "Command cancelled"
+	return m
+}
+
+// copyableOutput returns the unstyled output for copy/save features, with or
+// without the FormatOutput header (Command/Started/Duration/Exit Code).
+// The spinner line shown while streaming is never included.
+func copyableOutput(m model.Model, includeHeader bool) string {
+	if includeHeader && !m.Spinning {
+		return StripANSI(m.ExecutionOutput)
+	}
+	return StripANSI(m.BatchOutput + streamedDisplay(m))
+}
+
+// handleSelectionKeyPress moves the selection cursor and copies the selected lines
+func handleSelectionKeyPress(msg tea.KeyMsg, m model.Model, outputLines []string, visibleLines int) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "v":
+		m.SelectionActive = false
+	case "up", "k":
+		if m.SelectionCursor > 0 {
+			m.SelectionCursor--
+		}
+	case "down", "j":
+		if m.SelectionCursor < len(outputLines)-1 {
+			m.SelectionCursor++
+		}
+	case "Y":
+		start, end := m.SelectionAnchor, m.SelectionCursor
+		if start > end {
+			start, end = end, start
+		}
+		if end >= len(outputLines) {
+			end = len(outputLines) - 1
+		}
+		if err := copyToClipboard(strings.Join(outputLines[start:end+1], "\n")); err != nil {
+			m.Error = fmt.Sprintf("Failed to copy selection: %v", err)
+			return m, nil
+		}
+		m.SelectionActive = false
+		return flash(m, fmt.Sprintf("Copied %d line(s) to the clipboard", end-start+1))
+	}
+
+	// Keep the selection cursor inside the visible window
+	if m.SelectionCursor < m.OutputScrollPosition {
+		m.OutputScrollPosition = m.SelectionCursor
+	} else if m.SelectionCursor >= m.OutputScrollPosition+visibleLines {
+		m.OutputScrollPosition = m.SelectionCursor - visibleLines + 1
 	}
 	return m, nil
 }
@@ -287,10 +869,23 @@ func handleFormKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 		m.ShowForm = false
 		return m, nil
 	case "enter":
+		if m.ActiveFormField.Choices() != nil {
+			cycleFormField(&m, 1)
+			return m, nil
+		}
+		if m.ActiveFormField == model.FieldWorkingDirPath && !m.FormCommand.UsesWorkingDirPath() {
+			m.Error = "WorkingDirPath is only used when WorkingDirMode is absolute or relative"
+			return m, nil
+		}
 		// Start editing the current field
 		m.EditingFormField = true
 		m.FormInputBuffer = m.GetFormFieldValue(m.ActiveFormField)
+		m.FormCursor = utf8.RuneCountInString(m.FormInputBuffer)
 		return m, nil
+	case "right", " ":
+		cycleFormField(&m, 1)
+	case "left":
+		cycleFormField(&m, -1)
 	case "y":
 		// Save the command
 		return saveFormCommand(m)
@@ -319,6 +914,25 @@ func handleFormKeyPress(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 	return m, nil
 }
 
+// cycleFormField steps the active field to the next (step 1) or previous
+// (step -1) of its choices; fields without choices are left alone
+func cycleFormField(m *model.Model, step int) {
+	choices := m.ActiveFormField.Choices()
+	if choices == nil {
+		return
+	}
+	// An empty or unknown value counts as the first choice
+	current := 0
+	value := m.GetFormFieldValue(m.ActiveFormField)
+	for i, choice := range choices {
+		if strings.EqualFold(choice, strings.TrimSpace(value)) {
+			current = i
+		}
+	}
+	next := (current + step + len(choices)) % len(choices)
+	m.SetFormFieldValue(m.ActiveFormField, choices[next])
+}
+
 // handleFormFieldEdit handles key presses when editing a form field
 func handleFormFieldEdit(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 	switch msg.String() {
@@ -338,20 +952,38 @@ func handleFormFieldEdit(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 			m.ActiveFormField++
 		}
 		return m, nil
+	case "ctrl+j", "alt+enter":
+		// Only the command may span several lines, e.g. for a heredoc; the
+		// shell runs it as a script
+		if m.ActiveFormField == model.FieldCommand {
+			insertAtCursor(&m, "\n")
+		}
 	case "backspace":
-		// Delete last character
-		if len(m.FormInputBuffer) > 0 {
-			m.FormInputBuffer = m.FormInputBuffer[:len(m.FormInputBuffer)-1]
+		// Delete the character before the cursor
+		if m.FormCursor > 0 {
+			runes := []rune(m.FormInputBuffer)
+			m.FormInputBuffer = string(runes[:m.FormCursor-1]) + string(runes[m.FormCursor:])
+			m.FormCursor--
+		}
+	case "delete", "ctrl+d":
+		// Delete the character under the cursor
+		if runes := []rune(m.FormInputBuffer); m.FormCursor < len(runes) {
+			m.FormInputBuffer = string(runes[:m.FormCursor]) + string(runes[m.FormCursor+1:])
 		}
 	case "ctrl+u":
 		// Clear entire input
 		m.FormInputBuffer = ""
-	case "ctrl+a":
-		// Select all - not really meaningful in our case, just clear and start fresh
-		m.FormInputBuffer = ""
-	case "ctrl+e":
-		// Not really meaningful in our simple editor, but we're supporting the shortcut for consistency
-		// In a real implementation, this would move cursor to end of input
+		m.FormCursor = 0
+	case "left":
+		m.FormCursor = max(m.FormCursor-1, 0)
+	case "right":
+		m.FormCursor = min(m.FormCursor+1, utf8.RuneCountInString(m.FormInputBuffer))
+	case "home", "ctrl+a":
+		// Start of the line, which is the whole input unless the command
+		// spans several lines
+		m.FormCursor, _ = cursorLineBounds(m)
+	case "end", "ctrl+e":
+		_, m.FormCursor = cursorLineBounds(m)
 	case "tab":
 		// Confirm and move to next field
 		m.SetFormFieldValue(m.ActiveFormField, m.FormInputBuffer)
@@ -370,23 +1002,73 @@ func handleFormFieldEdit(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
 			m.ActiveFormField--
 		}
 		return m, nil
-	case "up", "down", "left", "right":
-		// Ignore arrow keys in edit mode
+	case "up", "down":
+		// Ignore vertical arrow keys in edit mode
 		return m, nil
 	default:
-		// Handle regular key inputs (ignore special keys)
-		if len(msg.String()) == 1 || msg.String() == "space" {
-			if msg.String() == "space" {
-				m.FormInputBuffer += " "
-			} else {
-				m.FormInputBuffer += msg.String()
-			}
+		// Handle typed and pasted text (ignore special keys); only the
+		// command keeps the line breaks of a paste
+		if text := typedText(msg, m.ActiveFormField == model.FieldCommand); text != "" {
+			insertAtCursor(&m, text)
 		}
 	}
 
 	return m, nil
 }
 
+// insertAtCursor inserts s into the form input at the cursor and moves the
+// cursor past it
+func insertAtCursor(m *model.Model, s string) {
+	runes := []rune(m.FormInputBuffer)
+	m.FormCursor = min(m.FormCursor, len(runes))
+	m.FormInputBuffer = string(runes[:m.FormCursor]) + s + string(runes[m.FormCursor:])
+	m.FormCursor += utf8.RuneCountInString(s)
+}
+
+// cursorLineBounds returns the rune positions where the line of the form
+// input holding the cursor starts and ends
+func cursorLineBounds(m model.Model) (int, int) {
+	runes := []rune(m.FormInputBuffer)
+	start := min(m.FormCursor, len(runes))
+	for start > 0 && runes[start-1] != '\n' {
+		start--
+	}
+	end := min(m.FormCursor, len(runes))
+	for end < len(runes) && runes[end] != '\n' {
+		end++
+	}
+	return start, end
+}
+
+// updateFormPreview resolves the working directory path being typed so the
+// form can show what ~, $HOME and ${cwd} expand to, and where a relative path
+// ends up
+func updateFormPreview(m *model.Model) {
+	m.FormPreview = ""
+	m.FormPreviewError = false
+	if !m.EditingFormField || m.ActiveFormField != model.FieldWorkingDirPath || strings.TrimSpace(m.FormInputBuffer) == "" {
+		return
+	}
+
+	expanded, err := expandWorkingDirPath(m.FormInputBuffer, strings.ToLower(strings.TrimSpace(m.FormCommand.WorkingDirMode)))
+	switch {
+	case err != nil:
+		m.FormPreview = err.Error()
+		m.FormPreviewError = true
+	case !filepath.IsAbs(expanded):
+		m.FormPreview = expanded + " (not an absolute path)"
+		m.FormPreviewError = true
+	default:
+		m.FormPreview = expanded
+		if fi, statErr := os.Stat(expanded); os.IsNotExist(statErr) && m.FormCommand.CreateWorkingDir {
+			m.FormPreview += " (will be created)"
+		} else if statErr != nil || !fi.IsDir() {
+			m.FormPreview += " (does not exist)"
+			m.FormPreviewError = true
+		}
+	}
+}
+
 // saveFormCommand validates and saves the form command
 func saveFormCommand(m model.Model) (model.Model, tea.Cmd) {
 	// Validate
@@ -397,7 +1079,7 @@ func saveFormCommand(m model.Model) (model.Model, tea.Cmd) {
 
 	// Generate ID if new command
 	if m.FormCommand.ID == "" {
-		m.FormCommand.ID = fmt.Sprintf("%d", time.Now().Unix())
+		m.FormCommand.ID = config.NewCommandID(m.AllCommands)
 
 		// If this is a new command and category is not set, use default
 		if m.FormCommand.Category == "" {
@@ -410,6 +1092,46 @@ func saveFormCommand(m model.Model) (model.Model, tea.Cmd) {
 		}
 	}
 
+	if err := ValidateWorkingDir(m.FormCommand); err != nil {
+		m.Error = err.Error()
+		return m, nil
+	}
+	m.FormCommand.WorkingDirMode = strings.ToLower(strings.TrimSpace(m.FormCommand.WorkingDirMode))
+	if m.FormCommand.TimeoutSeconds < 0 {
+		m.Error = "TimeoutSeconds must be a whole number of seconds (0 for no timeout)"
+		return m, nil
+	}
+	if m.FormCommand.Retries < 0 || m.FormCommand.RetryDelaySeconds < 0 {
+		m.Error = "Retries and RetryDelaySeconds must be whole numbers (0 or more)"
+		return m, nil
+	}
+	if err := ValidateEnv(m.FormCommand.Env); err != nil {
+		m.Error = err.Error()
+		return m, nil
+	}
+	if err := validateRedactPatterns(m.FormCommand.Redact); err != nil {
+		m.Error = err.Error()
+		return m, nil
+	}
+	if err := ValidateColorMode(m.FormCommand.ColorMode); err != nil {
+		m.Error = err.Error()
+		return m, nil
+	}
+	if m.FormCommand.Shell != "" {
+		if _, err := exec.LookPath(m.FormCommand.Shell); err != nil {
+			m.Error = fmt.Sprintf("Shell %q was not found", m.FormCommand.Shell)
+			return m, nil
+		}
+	}
+
+	// Keep tags clean so filtering doesn't see near-duplicates
+	m.FormCommand.Tags = model.NormalizeTags(m.FormCommand.Tags, m.Settings.LowercaseTags)
+	m.FormCommand.Aliases = model.NormalizeTags(m.FormCommand.Aliases, false)
+	if alias, other := aliasTaken(m.AllCommands, m.FormCommand); other != nil {
+		m.Error = fmt.Sprintf("Alias %q is already used by %q", alias, other.Name)
+		return m, nil
+	}
+
 	// Update or add command
 	found := false
 	for i, cmd := range m.AllCommands {
@@ -423,15 +1145,23 @@ func saveFormCommand(m model.Model) (model.Model, tea.Cmd) {
 	if !found {
 		m.AllCommands = append(m.AllCommands, m.FormCommand)
 	}
+	// Restoring a deleted command after other edits could bring back stale data
+	m.DeletedCommand = nil
 
 	// Update categories and visible commands
-	m.Categories = config.GetCategories(m.AllCommands)
-	m.VisibleCommands = filterCommands(m)
+	m.Categories = config.GetCategories(m.AllCommands, m.Settings.CategoryOrder)
+	applyFilter(&m)
 
-	// Save configuration
-	if err := config.SaveConfig(m.AllCommands); err != nil {
-		m.Error = fmt.Sprintf("Failed to save config: %v", err)
-		return m, nil
+	// Save configuration; on failure the edit is kept in memory and flagged
+	saveCommands(&m)
+	if !m.UnsavedChanges {
+		if err := validateExecutable(m.FormCommand); err != nil {
+			m.Error = fmt.Sprintf("Saved, but %v", err)
+		} else if other := sameNamedCommand(m.AllCommands, m.FormCommand); other != nil {
+			m.Error = fmt.Sprintf("Saved, but another command (ID %s) is also named %q", other.ID, m.FormCommand.Name)
+		} else if warnings := config.LintCommand(m.FormCommand); len(warnings) > 0 {
+			m.Error = fmt.Sprintf("Saved, but %q %s", m.FormCommand.Name, warnings[0])
+		}
 	}
 
 	// Exit form mode
@@ -439,44 +1169,202 @@ func saveFormCommand(m model.Model) (model.Model, tea.Cmd) {
 	return m, nil
 }
 
-// executeCommand executes a command and returns the result
-func executeCommand(command model.Command, m model.Model) (model.Model, tea.Cmd) {
-	// Mark as executing
-	m.Executing = true
-	m.ExecutingCommand = &command
-	m.ExecutionOutput = ""
-	m.OutputScrollPosition = 0 // Reset scroll position when starting a new command
-	m.Error = ""
+// sameNamedCommand returns another command with the same name as command
+// (ignoring case and accents), or nil. Names don't have to be unique, but duplicates
+// make the list and `go-recipe run <name>` ambiguous.
+func sameNamedCommand(commands []model.Command, command model.Command) *model.Command {
+	for i, other := range commands {
+		if other.ID != command.ID && model.FoldEqual(strings.TrimSpace(other.Name), strings.TrimSpace(command.Name)) {
+			return &commands[i]
+		}
+	}
+	return nil
+}
 
-	// Interactive commands: suspend TUI and hand over TTY to the process; return when it exits
-	if command.Interactive {
-		// Build exec.Cmd to attach current TTY via ExecProcess
-		var cmd *exec.Cmd
-		if command.UseShell || command.Interactive {
-			shell := os.Getenv("SHELL")
-			if shell == "" {
-				shell = "bash"
+// aliasTaken returns the first alias of command that another command already
+// uses (ignoring case and accents), together with that command, or nil
+func aliasTaken(commands []model.Command, command model.Command) (string, *model.Command) {
+	for _, alias := range command.Aliases {
+		for i, other := range commands {
+			if other.ID == command.ID {
+				continue
 			}
-			cmd = exec.Command(shell, "-lc", command.Command)
-		} else {
-			parts := strings.Fields(command.Command)
-			if len(parts) == 0 {
-				m.Error = "empty command"
-				m.Executing = false
-				m.ExecutingCommand = nil
-				return m, nil
+			for _, otherAlias := range other.Aliases {
+				if model.FoldEqual(alias, otherAlias) {
+					return alias, &commands[i]
+				}
 			}
-			cmd = exec.Command(parts[0], parts[1:]...)
-		}
-		if dir, derr := resolveWorkingDir(command); derr == nil && dir != "" {
-			cmd.Dir = dir
-		} else if derr != nil {
-			m.Error = fmt.Sprintf("Failed to resolve working dir: %v", derr)
-			m.Executing = false
-			m.ExecutingCommand = nil
-			return m, nil
 		}
-		cmd.Stdin = os.Stdin
+	}
+	return "", nil
+}
+
+// validateExecutable checks that the program a command starts can be found on
+// PATH. Commands run via the shell are skipped since they may use builtins or
+// aliases, and so are relative paths, which depend on the working directory.
+func validateExecutable(cmd model.Command) error {
+	if cmd.UseShell || cmd.Interactive {
+		return nil
+	}
+	fields := strings.Fields(cmd.Command)
+	if len(fields) == 0 {
+		return nil
+	}
+	program := fields[0]
+	if strings.ContainsRune(program, '/') && !filepath.IsAbs(program) {
+		return nil
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return fmt.Errorf("%q was not found on PATH", program)
+	}
+	return nil
+}
+
+// saveCommands writes all commands to disk. When that fails (even after
+// config retries transient errors) the changes are kept in memory and
+// UnsavedChanges is set so the UI keeps warning until a save succeeds.
+func saveCommands(m *model.Model) {
+	if err := config.SaveConfig(m.AllCommands); err != nil {
+		m.UnsavedChanges = true
+		m.Error = fmt.Sprintf("Failed to save config: %v", err)
+		return
+	}
+	m.UnsavedChanges = false
+}
+
+// recordRun stamps the command's last run time and bumps its run count
+func recordRun(m *model.Model, id string) {
+	now := time.Now()
+	recordRunStats(m, id, func(stats *model.RunStats) {
+		stats.LastRun = now
+		stats.RunCount++
+	})
+}
+
+// recordExit stores the exit code of a finished run on its command for the
+// list's last run indicator
+func recordExit(m *model.Model, result Result) {
+	code := result.ExitCode
+	recordRunStats(m, result.Command.ID, func(stats *model.RunStats) {
+		stats.LastExit = &code
+	})
+}
+
+// recordRunStats updates the command's run stats in the state file, leaving
+// the commands file alone, and shows them in the list
+func recordRunStats(m *model.Model, id string, record func(*model.RunStats)) {
+	for i, cmd := range m.AllCommands {
+		if cmd.ID != id {
+			continue
+		}
+		stats, err := config.RecordRun(id, record)
+		if err != nil {
+			m.Error = fmt.Sprintf("Failed to record the run: %v", err)
+			stats = model.RunStats{LastRun: cmd.LastRun, RunCount: cmd.RunCount, LastExit: cmd.LastExit}
+			record(&stats)
+		}
+		m.AllCommands[i].LastRun = stats.LastRun
+		m.AllCommands[i].RunCount = stats.RunCount
+		m.AllCommands[i].LastExit = stats.LastExit
+		refreshVisible(m)
+		return
+	}
+}
+
+// refreshVisible re-applies filtering and sorting, keeping the selected
+// command selected when it is still visible
+func refreshVisible(m *model.Model) {
+	selectedID := ""
+	if m.SelectedIndex < len(m.VisibleCommands) {
+		selectedID = m.VisibleCommands[m.SelectedIndex].ID
+	}
+	applyFilter(m)
+	for i, cmd := range m.VisibleCommands {
+		if cmd.ID == selectedID {
+			m.SelectedIndex = i
+			return
+		}
+	}
+}
+
+// applyFilter recomputes the visible commands and keeps the selection within
+// the new list
+func applyFilter(m *model.Model) {
+	m.VisibleCommands = filterCommands(*m)
+	m.SelectedIndex = max(min(m.SelectedIndex, len(m.VisibleCommands)-1), 0)
+}
+
+// pageList moves the list window and the selection a screenful up or down,
+// keeping the selection at the same place on screen until an end is reached
+func pageList(m *model.Model, down bool) {
+	if len(m.VisibleCommands) == 0 {
+		return
+	}
+	start, end := m.ListWindow(m.ListRows())
+	page := max(end-start, 1)
+	if !down {
+		page = -page
+	}
+	last := len(m.VisibleCommands) - 1
+	m.ListOffset = max(min(start+page, last), 0)
+	m.SelectedIndex = max(min(m.SelectedIndex+page, last), 0)
+}
+
+// scrollListToSelection moves the list window only as far as needed to keep
+// the selected command on screen
+func scrollListToSelection(m *model.Model) {
+	m.ListOffset, _ = m.ListWindow(m.ListRows())
+}
+
+// executeCommand executes a command and returns the result
+func executeCommand(command model.Command, m model.Model) (model.Model, tea.Cmd) {
+	// Mark as executing
+	m.Executing = true
+	m.ExecutingCommand = &command
+	m.ExecutionOutput = ""
+	m.OutputScrollPosition = 0 // Reset scroll position when starting a new command
+	m.FollowOutput = false
+	m.SelectionActive = false
+	stopWatching(&m)
+	m.Error = ""
+	m.Info = ""
+	m.BatchQueue = nil
+	m.BatchIndex = 0
+	m.BatchTotal = 0
+	m.BatchOutput = ""
+	m.AlternateOutput = ""
+	m.ViewingLastResult = false
+	m.ViewingDryRun = false
+	recordRun(&m, command.ID)
+
+	// Interactive commands: suspend TUI and hand over TTY to the process; return when it exits.
+	// This takes precedence over background mode, which can't host a TUI.
+	if command.Interactive {
+		if m.RunInBackground {
+			m.Info = "Interactive commands can't run in background; ran attached instead"
+		}
+		if m.Settings.OpenInTerminal {
+			m.Executing = false
+			m.ExecutingCommand = nil
+			if err := openInTerminal(command); err != nil {
+				m.Error = fmt.Sprintf("Failed to open %q in a new terminal: %v", command.Name, err)
+			} else {
+				m.Info = fmt.Sprintf("Opened %q in a new terminal window", command.Name)
+			}
+			return m, nil
+		}
+		if m.Settings.InteractivePTY {
+			return startPTYSession(command, m)
+		}
+		// Build exec.Cmd to attach current TTY via ExecProcess
+		cmd, err := newExecCmd(context.Background(), command, true)
+		if err != nil {
+			m.Error = fmt.Sprintf("Failed to prepare command: %v", err)
+			m.Executing = false
+			m.ExecutingCommand = nil
+			return m, nil
+		}
+		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		m.Executing = false
@@ -497,33 +1385,33 @@ func executeCommand(command model.Command, m model.Model) (model.Model, tea.Cmd)
 
 	// If background mode is enabled (and not interactive), run in background
 	if m.RunInBackground {
-		// create logs dir and file
-		logPath, err := createBackgroundLogFile(command)
-		if err != nil {
-			m.Error = fmt.Sprintf("Failed to create background log: %v", err)
-			m.Executing = false
-			m.ExecutingCommand = nil
-			m.ExecutionOutput = ""
-			return m, nil
-		}
-		go func(p string) {
-			f, ferr := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-			if ferr != nil {
-				return
-			}
-			defer f.Close()
-			_ = ExecuteCommandStreaming(command, f)
-		}(logPath)
-		// show info message
-		// Use Error field for now to surface message in UI if Info is not present
-		m.Error = fmt.Sprintf("Background task started. Log: %s", logPath)
 		m.Executing = false
 		m.ExecutingCommand = nil
 		m.ExecutionOutput = ""
-		return m, nil
+		m, cmd := startBackground(command, m)
+		if cmd != nil {
+			m.Info = fmt.Sprintf("Background task started. Log: %s", m.Tasks[len(m.Tasks)-1].LogPath)
+		}
+		return m, cmd
 	}
 
 	// Foreground: start streaming to a temp file and poll
+	m, runCmd := startForeground(command, m)
+	if runCmd == nil {
+		return m, nil
+	}
+
+	// Start polling ticks
+	poll := tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg { return StreamPollMsg{} })
+	spin := tea.Tick(120*time.Millisecond, func(time.Time) tea.Msg { return SpinnerTickMsg{} })
+	elapsed := tea.Tick(time.Second, func(time.Time) tea.Msg { return ElapsedTickMsg{} })
+	return m, tea.Batch(runCmd, poll, spin, elapsed)
+}
+
+// startForeground starts streaming the command's output to a temp file and
+// returns the runner that reports the result, or nil if it couldn't start.
+// The caller keeps the poll and spinner ticks going.
+func startForeground(command model.Command, m model.Model) (model.Model, tea.Cmd) {
 	tmpFile, err := os.CreateTemp("", "go-recipe-stream-*.log")
 	if err != nil {
 		m.Error = fmt.Sprintf("Failed to create temp log: %v", err)
@@ -533,94 +1421,357 @@ func executeCommand(command model.Command, m model.Model) (model.Model, tea.Cmd)
 	}
 	tmpPath := tmpFile.Name()
 	tmpFile.Close()
+	stopTailing(&m)
+	removeTempLog(&m)
 	m.ExecutionLogPath = tmpPath
-	m.ExecutionLogOffset = 0
+	m.ExecutionTempLog = tmpPath
+	m.ExecutionStart = time.Now()
+	m.ExecutionElapsed = 0
 	m.Spinning = true
-	m.StreamedOutput = ""
+	m.StreamedOutput = model.NewOutputBuffer(m.Settings.OutputMaxLines)
+
+	// Cancelled with x or ctrl+c from the execution view
+	ctx, cancel := context.WithCancel(context.Background())
+	m.ExecutionCancel = cancel
 
 	// Command runner returns result when finished
+	settings := m.Settings
 	runCmd := func() tea.Msg {
 		f, ferr := os.OpenFile(tmpPath, os.O_WRONLY|os.O_APPEND, 0644)
 		if ferr != nil {
 			return CommandResultMsg{Result: Result{Command: command, Error: ferr, StartTime: time.Now(), EndTime: time.Now(), ExitCode: -1}}
 		}
 		defer f.Close()
-		res := ExecuteCommandStreaming(command, f)
-
-		// Update command's last run time
-		for i, cmd := range m.AllCommands {
-			if cmd.ID == command.ID {
-				m.AllCommands[i].LastRun = time.Now()
-				break
-			}
+		if !hasRedaction(command, settings) {
+			return CommandResultMsg{Result: ExecuteCommandStreamingContext(ctx, command, f)}
 		}
-		_ = config.SaveConfig(m.AllCommands)
-
+		// Mask secrets before they are written to the log, which outlives
+		// the run when quitting or paging
+		w := &redactWriter{w: f, redact: func(s string) string { return RedactOutput(s, command, settings) }}
+		res := ExecuteCommandStreamingContext(ctx, command, w)
+		_ = w.Flush()
 		return CommandResultMsg{Result: res}
 	}
-
-	// Start polling ticks
-	poll := tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg { return StreamPollMsg{} })
-	spin := tea.Tick(120*time.Millisecond, func(time.Time) tea.Msg { return SpinnerTickMsg{} })
-	return m, tea.Batch(runCmd, poll, spin)
+	return m, runCmd
 }
 
 // handleCommandResult processes the result of a command execution
 func handleCommandResult(result Result, m model.Model) (model.Model, tea.Cmd) {
-	// If we were streaming to a file, read it and compose final output
-	if m.ExecutionLogPath != "" {
-		content, _ := os.ReadFile(m.ExecutionLogPath)
-		result.Output = string(content)
+	// Ignore the result of a run the user already left for another command
+	if m.Executing && m.ExecutingCommand != nil && m.ExecutingCommand.ID != result.Command.ID {
+		return m, nil
 	}
-	// Stop spinner and show final output
+	if m.ExecutionCancel != nil {
+		m.ExecutionCancel()
+		m.ExecutionCancel = nil
+	}
+	// The user chose to quit once the command was killed
+	if m.QuitAfterRun {
+		stopTailing(&m)
+		removeTempLog(&m)
+		_ = RecordHistory(result)
+		return m, tea.Quit
+	}
+	// If we were streaming to a file, the polls kept its last lines; add the
+	// bytes the last poll didn't get to rather than reading the whole log
+	logPath, streamed := m.ExecutionLogPath, false
+	if logPath != "" {
+		if m.Screen != nil {
+			// An interactive command leaves what it drew on its screen
+			copyLogTail(&m, m.Screen)
+			result.Output = m.Screen.Transcript()
+		} else {
+			copyLogTail(&m, &m.StreamedOutput)
+			result.Output = displayableOutput(bufferedText(m.StreamedOutput))
+			streamed = true
+		}
+	}
+	// The log was masked as it was written; this catches what a screen
+	// transcript or an eager pseudo-terminal log let through
+	if hasRedaction(result.Command, m.Settings) {
+		result.Output = RedactOutput(result.Output, result.Command, m.Settings)
+	}
+	if m.Settings.SaveResults && !m.ViewingLastResult {
+		if err := saveResult(result); err != nil {
+			m.Error = fmt.Sprintf("Failed to store the result: %v", err)
+		}
+	}
+	if !m.ViewingLastResult {
+		if err := RecordHistory(result); err != nil {
+			m.Error = fmt.Sprintf("Failed to record the run in the history: %v", err)
+		}
+		recordExit(&m, result)
+	}
+	// Only the last output_max_lines lines are kept in memory; a streamed
+	// run's buffer already holds them
+	if !streamed {
+		m.StreamedOutput = boundedOutput(displayableOutput(result.Output), m.Settings)
+		result.Output = bufferedText(m.StreamedOutput)
+	}
+	if result.Stderr != "" {
+		stderr := boundedOutput(RedactOutput(result.Stderr, result.Command, m.Settings), m.Settings)
+		result.Stderr = displayableOutput(stderr.String())
+	}
+
+	// Stop spinner and the timer, and show final output
 	m.Spinning = false
-	m.StreamedOutput = result.Output
-	m.ExecutionOutput = FormatOutput(result)
+	m.ExecutionElapsed = result.EndTime.Sub(result.StartTime)
+	formatted := FormatOutput(result)
+	m.ExecutionOutput = m.BatchOutput + formatted
+	m.AlternateOutput = ""
+	if result.Stderr != "" {
+		// Keep the other layout ready for e
+		m.AlternateOutput = m.BatchOutput + FormatOutputSeparated(result)
+		if m.SeparateStderr {
+			m.ExecutionOutput, m.AlternateOutput = m.AlternateOutput, m.ExecutionOutput
+		}
+	}
+
+	// The result header replaces the single spinner line above the output;
+	// shift the scroll position so the same output lines stay in view
+	if m.FollowOutput {
+		m.OutputScrollPosition = maxOutputScroll(m)
+	} else if m.OutputScrollPosition > strings.Count(m.BatchOutput, "\n") {
+		if idx := strings.Index(formatted, outputMarker); idx >= 0 {
+			headerLines := strings.Count(formatted[:idx+len(outputMarker)], "\n")
+			m.OutputScrollPosition += headerLines - 1
+		}
+	}
 	// Keep the execution view open so the user can read/scroll the output
-	// Stop polling by clearing the log path and offset; leave Executing true
-	m.ExecutionLogPath = ""
-	m.ExecutionLogOffset = 0
+	// Stop polling and close the log; leave Executing true. The pseudo-terminal
+	// of an interactive run was closed once it exited.
+	stopTailing(&m)
+	m.PTY = nil
+	m.Screen = nil
+
+	if len(m.BatchQueue) > 0 {
+		return nextInBatch(result, m)
+	}
+
+	// Hand the captured output to the user's pager when requested
+	if result.Command.OpenOutputInEditor && logPath != "" {
+		cmd, err := pagerCommand(logPath)
+		if err != nil {
+			m.Error = err.Error()
+			return m, nil
+		}
+		return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return PagerClosedMsg{Error: err}
+		})
+	}
 	return m, nil
 }
 
+// pagerCommand builds the command that opens path in $PAGER or $EDITOR,
+// falling back to less or more
+func pagerCommand(path string) (*exec.Cmd, error) {
+	for _, env := range []string{"PAGER", "EDITOR"} {
+		if parts := strings.Fields(os.Getenv(env)); len(parts) > 0 {
+			return exec.Command(parts[0], append(parts[1:], path)...), nil
+		}
+	}
+	for _, pager := range []string{"less", "more"} {
+		if p, err := exec.LookPath(pager); err == nil {
+			return exec.Command(p, path), nil
+		}
+	}
+	return nil, fmt.Errorf("no pager found: set $PAGER or $EDITOR")
+}
+
 // filterCommands filters the command list based on category and filter text
 func filterCommands(m model.Model) []model.Command {
 	var filtered []model.Command
 
 	for _, command := range m.AllCommands {
 		// Apply category filter if not "All"
-		if m.ActiveCategory != "" && m.ActiveCategory != "All" && command.Category != m.ActiveCategory {
+		if !InCategory(command, m.ActiveCategory) {
+			continue
+		}
+		if !hasTags(command, m.ActiveTags, m.MatchAllTags) {
 			continue
 		}
 
-		// Apply text filter if present
-		if m.FilterText != "" {
-			lowerFilter := strings.ToLower(m.FilterText)
+		filtered = append(filtered, command)
+	}
 
-			// Check name, command, and description
-			nameMatch := strings.Contains(strings.ToLower(command.Name), lowerFilter)
-			cmdMatch := strings.Contains(strings.ToLower(command.Command), lowerFilter)
-			descMatch := strings.Contains(strings.ToLower(command.Description), lowerFilter)
+	sortCommands(filtered, m.SortMode)
+	// Fuzzy text filter; the best match comes first
+	if strings.TrimSpace(m.FilterText) != "" {
+		filtered = rankByScore(filtered, m.FilterText)
+	}
+	if m.GroupedList() {
+		groupByCategory(filtered, m.Categories)
+	}
+	return filtered
+}
 
-			// Check tags
-			tagMatch := false
-			for _, tag := range command.Tags {
-				if strings.Contains(strings.ToLower(tag), lowerFilter) {
-					tagMatch = true
-					break
-				}
-			}
+// groupByCategory orders commands by their category's position in categories
+// so each category's commands are listed together; within a category the
+// existing order is kept. Commands without a category come last.
+func groupByCategory(commands []model.Command, categories []string) {
+	position := func(category string) int {
+		if i := slices.Index(categories, category); i >= 0 {
+			return i
+		}
+		return len(categories)
+	}
+	sort.SliceStable(commands, func(i, j int) bool {
+		return position(commands[i].Category) < position(commands[j].Category)
+	})
+}
 
-			// Skip if no match found
-			if !nameMatch && !cmdMatch && !descMatch && !tagMatch {
-				continue
+// sortCommands orders commands in place for the given sort mode; ties and
+// SortConfig keep the config file order
+func sortCommands(commands []model.Command, mode model.SortMode) {
+	switch mode {
+	case model.SortName:
+		sort.SliceStable(commands, func(i, j int) bool {
+			return strings.ToLower(commands[i].Name) < strings.ToLower(commands[j].Name)
+		})
+	case model.SortRecent:
+		sort.SliceStable(commands, func(i, j int) bool {
+			return commands[i].LastRun.After(commands[j].LastRun)
+		})
+	case model.SortFrequent:
+		sort.SliceStable(commands, func(i, j int) bool {
+			return commands[i].RunCount > commands[j].RunCount
+		})
+	}
+}
+
+// InCategory reports whether the command belongs to category; an empty
+// category or "All" matches every command and "Favorites" the favorite ones
+func InCategory(command model.Command, category string) bool {
+	if category == model.FavoritesCategory {
+		return command.Favorite
+	}
+	return category == "" || category == "All" || command.Category == category
+}
+
+// searchFields are the command fields the filter searches, in order of the
+// weight a match in them carries: a word found in the name or an alias
+// outranks one found in the command text or a tag, which outranks the
+// remaining fields
+var searchFields = []struct {
+	weight int
+	values func(model.Command) []string
+}{
+	{3, func(c model.Command) []string { return []string{c.Name} }},
+	{3, func(c model.Command) []string { return c.Aliases }},
+	{2, func(c model.Command) []string { return []string{c.Command} }},
+	{2, func(c model.Command) []string { return c.Tags }},
+	{1, func(c model.Command) []string { return []string{c.Description} }},
+	{1, func(c model.Command) []string { return []string{c.Category} }},
+	{1, func(c model.Command) []string { return []string{c.WorkingDirPath} }},
+}
+
+// matchScore rates how well query matches the command, 0 meaning no match.
+// Each whitespace-separated word of the query must fuzzy-match (ignoring
+// case and accents) one of the searchFields; the best weighted match of each word counts.
+func matchScore(command model.Command, query string) int {
+	total := 0
+	for _, word := range strings.Fields(query) {
+		best := 0
+		for _, field := range searchFields {
+			for _, value := range field.values(command) {
+				score, _ := model.FuzzyMatch(value, word)
+				best = max(best, score*field.weight)
 			}
 		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total
+}
 
-		filtered = append(filtered, command)
+// rankByScore keeps the commands matching query, best match first; equal
+// scores keep their existing order
+func rankByScore(commands []model.Command, query string) []model.Command {
+	type scored struct {
+		command model.Command
+		score   int
+	}
+	var matches []scored
+	for _, command := range commands {
+		if score := matchScore(command, query); score > 0 {
+			matches = append(matches, scored{command, score})
+		}
 	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
 
-	return filtered
+	ranked := make([]model.Command, len(matches))
+	for i, match := range matches {
+		ranked[i] = match.command
+	}
+	return ranked
+}
+
+// paletteResults returns the commands matching the palette query across all categories
+func paletteResults(commands []model.Command, query string) []model.Command {
+	if strings.TrimSpace(query) == "" {
+		return commands
+	}
+	return rankByScore(commands, query)
+}
+
+// handlePaletteMode handles key presses in the command palette overlay
+func handlePaletteMode(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+p":
+		// Close the palette; the underlying view state was never touched
+		m.CurrentMode = model.ModeNormal
+		m.PaletteResults = nil
+		return m, nil
+	case "up", "ctrl+k":
+		if m.PaletteIndex > 0 {
+			m.PaletteIndex--
+		}
+		return m, nil
+	case "down", "ctrl+j":
+		if m.PaletteIndex < len(m.PaletteResults)-1 {
+			m.PaletteIndex++
+		}
+		return m, nil
+	case "enter":
+		if m.PaletteIndex >= len(m.PaletteResults) {
+			return m, nil
+		}
+		if m.Executing && m.Spinning {
+			m.Error = "Wait for the running command to finish first"
+			return m, nil
+		}
+		command := m.PaletteResults[m.PaletteIndex]
+		m.CurrentMode = model.ModeNormal
+		m.PaletteResults = nil
+		if command.Confirm {
+			// The prompt lives in the list view, so leave any finished output
+			m.Executing = false
+			m.ExecutingCommand = nil
+			stopWatching(&m)
+			stopTailing(&m)
+			removeTempLog(&m)
+			m.CurrentMode = model.ModeConfirm
+			m.ConfirmCommand = &command
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			return ExecuteCommandMsg{Command: command}
+		}
+	case "backspace":
+		m.PaletteQuery = trimLastRune(m.PaletteQuery)
+	default:
+		text := typedText(msg, false)
+		if text == "" {
+			return m, nil
+		}
+		m.PaletteQuery += text
+	}
+
+	m.PaletteResults = paletteResults(m.AllCommands, m.PaletteQuery)
+	m.PaletteIndex = 0
+	return m, nil
 }
 
 // handleFilterInputMode handles key presses when in filter input mode
@@ -633,66 +1784,259 @@ func handleFilterInputMode(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd)
 	case "enter":
 		// Apply filter
 		m.FilterText = m.InputBuffer
-		m.VisibleCommands = filterCommands(m)
+		applyFilter(&m)
+		m.SelectedIndex = 0
 		m.CurrentMode = model.ModeNormal
 		m.InputBuffer = ""
 		return m, nil
 	case "backspace":
 		// Delete last character
 		if len(m.InputBuffer) > 0 {
-			m.InputBuffer = m.InputBuffer[:len(m.InputBuffer)-1]
-			// Update filter in real time
+			m.InputBuffer = trimLastRune(m.InputBuffer)
+			// Update filter in real time, selecting the best match
 			m.FilterText = m.InputBuffer
-			m.VisibleCommands = filterCommands(m)
+			applyFilter(&m)
+			m.SelectedIndex = 0
 		}
 	case "ctrl+u":
 		// Clear filter
 		m.InputBuffer = ""
 		m.FilterText = ""
-		m.VisibleCommands = filterCommands(m)
+		applyFilter(&m)
+		m.SelectedIndex = 0
 	default:
-		// Handle regular key inputs
-		if len(msg.String()) == 1 || msg.String() == "space" {
-			if msg.String() == "space" {
-				m.InputBuffer += " "
-			} else {
-				m.InputBuffer += msg.String()
-			}
-			// Update filter in real time
+		// Handle typed and pasted text
+		if text := typedText(msg, false); text != "" {
+			m.InputBuffer += text
+			// Update filter in real time, selecting the best match
 			m.FilterText = m.InputBuffer
-			m.VisibleCommands = filterCommands(m)
+			applyFilter(&m)
+			m.SelectedIndex = 0
 		}
 	}
 
 	return m, nil
 }
 
+// promptWatchInterval asks how often the command is re-run when watched
+func promptWatchInterval(command model.Command, m model.Model) model.Model {
+	m.CurrentMode = model.ModeWatchInterval
+	m.WatchCommand = &command
+	m.InputBuffer = "2"
+	return m
+}
+
+// handleWatchIntervalMode reads the watch interval and starts watching on enter
+func handleWatchIntervalMode(msg tea.KeyMsg, m model.Model) (model.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.CurrentMode = model.ModeNormal
+		m.WatchCommand = nil
+		m.InputBuffer = ""
+	case "enter":
+		interval, err := parseWatchInterval(m.InputBuffer)
+		if err != nil {
+			m.Error = err.Error()
+			return m, nil
+		}
+		command := *m.WatchCommand
+		m.CurrentMode = model.ModeNormal
+		m.WatchCommand = nil
+		m.InputBuffer = ""
+
+		m.Executing = true
+		m.ExecutingCommand = &command
+		m.ExecutionOutput = ""
+		m.StreamedOutput = model.NewOutputBuffer(m.Settings.OutputMaxLines)
+		m.OutputScrollPosition = 0
+		m.SelectionActive = false
+		m.Watching = true
+		m.ExecutionStart = time.Time{}
+		m.WatchInterval = interval
+		m.WatchLastRun = time.Time{}
+		m.WatchID++
+		return runWatch(command, m)
+	case "backspace":
+		m.InputBuffer = trimLastRune(m.InputBuffer)
+	default:
+		m.InputBuffer += typedText(msg, false)
+	}
+	return m, nil
+}
+
+// parseWatchInterval accepts plain seconds ("5") or a Go duration ("500ms", "1m")
+func parseWatchInterval(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if secs, err := strconv.Atoi(s); err == nil {
+		if secs <= 0 {
+			return 0, fmt.Errorf("watch interval must be positive")
+		}
+		return time.Duration(secs) * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid watch interval %q, use seconds or a duration like 500ms", s)
+	}
+	return d, nil
+}
+
+// runWatch executes one watch cycle in the background. Its cancel function is
+// kept on the model so stopping the watch kills a cycle that is still running.
+func runWatch(command model.Command, m model.Model) (model.Model, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.WatchCancel = cancel
+	id := m.WatchID
+	return m, func() tea.Msg {
+		return WatchResultMsg{ID: id, Result: ExecuteCommandContext(ctx, command)}
+	}
+}
+
+// stopWatching stops re-running the executing command and kills the watch run
+// in progress
+func stopWatching(m *model.Model) {
+	m.Watching = false
+	if m.WatchCancel != nil {
+		m.WatchCancel()
+		m.WatchCancel = nil
+	}
+}
+
+// handleWatchResult replaces the output with the latest run and schedules the next one
+func handleWatchResult(msg WatchResultMsg, m model.Model) (model.Model, tea.Cmd) {
+	if !m.Watching || msg.ID != m.WatchID {
+		return m, nil
+	}
+	if m.WatchCancel != nil {
+		m.WatchCancel()
+		m.WatchCancel = nil
+	}
+	m.StreamedOutput = boundedOutput(RedactOutput(msg.Result.Output, msg.Result.Command, m.Settings), m.Settings)
+	msg.Result.Output = bufferedText(m.StreamedOutput)
+	m.ExecutionOutput = FormatOutput(msg.Result)
+	m.WatchLastRun = msg.Result.EndTime
+	id := m.WatchID
+	return m, tea.Tick(m.WatchInterval, func(time.Time) tea.Msg { return WatchTickMsg{ID: id} })
+}
+
+// streamedDisplay returns the output streamed so far with redactions applied
+func streamedDisplay(m model.Model) string {
+	if m.ExecutingCommand == nil {
+		return displayableOutput(bufferedText(m.StreamedOutput))
+	}
+	return displayableOutput(RedactOutput(bufferedText(m.StreamedOutput), *m.ExecutingCommand, m.Settings))
+}
+
+// boundedOutput keeps the last output_max_lines lines of output
+func boundedOutput(output string, settings model.Settings) model.OutputBuffer {
+	buf := model.NewOutputBuffer(settings.OutputMaxLines)
+	buf.WriteString(output)
+	return buf
+}
+
+// bufferedText returns the output kept in buf, starting with a note when
+// earlier lines were dropped to stay within output_max_lines
+func bufferedText(buf model.OutputBuffer) string {
+	if buf.Dropped() == 0 {
+		return buf.String()
+	}
+	return fmt.Sprintf("(%d earlier lines dropped, only the last %d are kept; see output_max_lines)\n", buf.Dropped(), buf.MaxLines()) + buf.String()
+}
+
+// copyLogTail copies what was written to the execution log since the last
+// poll to w
+func copyLogTail(m *model.Model, w io.Writer) {
+	f := m.ExecutionLogFile
+	if f == nil {
+		var err error
+		if f, err = os.Open(m.ExecutionLogPath); err != nil {
+			return
+		}
+		defer f.Close()
+		if _, err := f.Seek(m.ExecutionLogOffset, io.SeekStart); err != nil {
+			return
+		}
+	}
+	n, _ := io.Copy(w, f)
+	m.ExecutionLogOffset += n
+}
+
+// stopTailing closes the log the execution view streams from and stops
+// polling it
+func stopTailing(m *model.Model) {
+	if m.ExecutionLogFile != nil {
+		_ = m.ExecutionLogFile.Close()
+		m.ExecutionLogFile = nil
+	}
+	m.ExecutionLogPath = ""
+	m.ExecutionLogOffset = 0
+}
+
+// removeTempLog deletes the temp log of the last foreground run, kept until
+// the execution view is left since the pager and the final output read it.
+// Logs of background runs opened from the logs view are never removed here.
+func removeTempLog(m *model.Model) {
+	if m.ExecutionTempLog == "" {
+		return
+	}
+	_ = os.Remove(m.ExecutionTempLog)
+	m.ExecutionTempLog = ""
+}
+
 // handleStreamPoll reads new bytes from the temp log and appends to output while executing
 func handleStreamPoll(m model.Model) (model.Model, tea.Cmd) {
 	if !m.Executing || m.ExecutionLogPath == "" {
 		return m, nil
 	}
-	f, err := os.Open(m.ExecutionLogPath)
-	if err != nil {
-		return m, tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg { return StreamPollMsg{} })
+	// The log stays open between polls, each one reading on from where the
+	// last stopped
+	if m.ExecutionLogFile == nil {
+		f, err := os.Open(m.ExecutionLogPath)
+		if err != nil {
+			return m, tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg { return StreamPollMsg{} })
+		}
+		if m.ExecutionLogOffset > 0 {
+			_, _ = f.Seek(m.ExecutionLogOffset, io.SeekStart)
+		}
+		m.ExecutionLogFile = f
 	}
-	defer f.Close()
-	// Seek to last offset
-	if m.ExecutionLogOffset > 0 {
-		_, _ = f.Seek(m.ExecutionLogOffset, io.SeekStart)
+	// A log truncated under us (e.g. rotated) is read again from the start
+	if info, err := m.ExecutionLogFile.Stat(); err == nil && info.Size() < m.ExecutionLogOffset {
+		_, _ = m.ExecutionLogFile.Seek(0, io.SeekStart)
+		m.ExecutionLogOffset = 0
+	}
+	// Read everything written since the last poll
+	data, _ := io.ReadAll(m.ExecutionLogFile)
+	// An interactive command in a pseudo-terminal draws its own screen,
+	// shown as it is instead of as lines of output
+	if m.Screen != nil {
+		_, _ = m.Screen.Write(data)
+		m.ExecutionLogOffset += int64(len(data))
+		m.ExecutionOutput = m.Screen.Render()
+		if m.ExecutingCommand != nil {
+			m.ExecutionOutput = RedactOutput(m.ExecutionOutput, *m.ExecutingCommand, m.Settings)
+		}
+		m.OutputScrollPosition = 0
+		return m, tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg { return StreamPollMsg{} })
 	}
-	buf := make([]byte, 64*1024)
-	n, _ := f.Read(buf)
-	if n > 0 {
-		m.StreamedOutput += string(buf[:n])
-		m.ExecutionLogOffset += int64(n)
+	dropped := m.StreamedOutput.Dropped()
+	if len(data) > 0 {
+		_, _ = m.StreamedOutput.Write(data)
+		m.ExecutionLogOffset += int64(len(data))
 	}
 	// Update ExecutionOutput with spinner + streamed content
 	frame := []string{"-", "\\", "|", "/"}[m.ExecutingAnimIndex%4]
+	shown := streamedDisplay(m)
 	if m.Spinning {
-		m.ExecutionOutput = fmt.Sprintf("%s\n%s", fmt.Sprintf("%s Executing...", frame), m.StreamedOutput)
+		m.ExecutionOutput = fmt.Sprintf("%s%s\n%s", m.BatchOutput, fmt.Sprintf("%s Executing...", frame), shown)
 	} else {
-		m.ExecutionOutput = m.StreamedOutput
+		m.ExecutionOutput = m.BatchOutput + shown
+	}
+	// Follow the tail, or keep the same top line anchored while lines are
+	// appended below and the oldest ones dropped above
+	if m.FollowOutput {
+		m.OutputScrollPosition = maxOutputScroll(m)
+	} else {
+		m.OutputScrollPosition = max(m.OutputScrollPosition-(m.StreamedOutput.Dropped()-dropped), 0)
 	}
 	// keep polling
 	return m, tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg { return StreamPollMsg{} })
@@ -700,17 +2044,10 @@ func handleStreamPoll(m model.Model) (model.Model, tea.Cmd) {
 
 // createBackgroundLogFile prepares a log file for background execution output
 func createBackgroundLogFile(cmd model.Command) (string, error) {
-	home, err := os.UserHomeDir()
+	path, err := timestampedPath("logs", cmd, ".log")
 	if err != nil {
 		return "", err
 	}
-	dir := filepath.Join(home, ".go-recipe", "logs")
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", err
-	}
-	ts := time.Now().Format("20060102-150405")
-	safeName := strings.ReplaceAll(cmd.Name, " ", "_")
-	path := filepath.Join(dir, fmt.Sprintf("%s-%s.log", safeName, ts))
 	f, err := os.Create(path)
 	if err != nil {
 		return "", err
@@ -718,3 +2055,31 @@ func createBackgroundLogFile(cmd model.Command) (string, error) {
 	f.Close()
 	return path, nil
 }
+
+// saveOutputFile writes output to a timestamped file under ~/.go-recipe/outputs
+func saveOutputFile(cmd model.Command, output string) (string, error) {
+	path, err := timestampedPath("outputs", cmd, ".txt")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// timestampedPath returns ~/.go-recipe/<subdir>/<name>-<timestamp><ext> for
+// the command, creating the directory if needed
+func timestampedPath(subdir string, cmd model.Command, ext string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".go-recipe", subdir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	ts := time.Now().Format("20060102-150405")
+	safeName := strings.ReplaceAll(cmd.Name, " ", "_")
+	return filepath.Join(dir, fmt.Sprintf("%s-%s%s", safeName, ts, ext)), nil
+}
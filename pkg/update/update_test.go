@@ -0,0 +1,618 @@
+package update
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Tomlord1122/go-recipe/pkg/config"
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+// press sends a key to the model the way Bubble Tea would
+func press(m model.Model, key string) model.Model {
+	var msg tea.KeyMsg
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	case "backspace":
+		msg = tea.KeyMsg{Type: tea.KeyBackspace}
+	case "ctrl+u":
+		msg = tea.KeyMsg{Type: tea.KeyCtrlU}
+	case "ctrl+s":
+		msg = tea.KeyMsg{Type: tea.KeyCtrlS}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	m, _ = Update(msg, m)
+	return m
+}
+
+// testModel returns a model listing commands, as if loaded from the config
+func testModel(commands ...model.Command) model.Model {
+	m := model.NewModel(false)
+	m.Width, m.Height = 100, 40
+	m.AllCommands = commands
+	refreshVisible(&m)
+	return m
+}
+
+func TestWatchAsksForPlaceholdersFirst(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := testModel(model.Command{ID: "1", Name: "ping", Command: "ping -c 1 {{host}}"})
+
+	m = press(m, "w")
+	if m.CurrentMode != model.ModePlaceholder {
+		t.Fatalf("mode = %v, want the placeholder prompt", m.CurrentMode)
+	}
+	m = press(m, "ctrl+u")
+	m = press(m, "example.com")
+	m = press(m, "enter")
+
+	if m.CurrentMode != model.ModeWatchInterval {
+		t.Fatalf("mode = %v, want the watch interval prompt", m.CurrentMode)
+	}
+	if got, want := m.WatchCommand.Command, "ping -c 1 example.com"; got != want {
+		t.Errorf("watched command = %q, want %q", got, want)
+	}
+}
+
+func TestStoppingAWatchKillsTheRunningCycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+	t.Setenv("HOME", t.TempDir())
+	m := testModel(model.Command{ID: "1", Name: "slow", Command: "sleep 30"})
+
+	m = press(m, "w")
+	m = press(m, "1")
+	m, cycle := Update(tea.KeyMsg{Type: tea.KeyEnter}, m)
+	if !m.Watching || cycle == nil {
+		t.Fatal("watch did not start")
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cycle() }()
+
+	m = press(m, "w")
+	if m.Watching || m.WatchCancel != nil {
+		t.Error("still watching after w")
+	}
+	select {
+	case msg := <-done:
+		if got := msg.(WatchResultMsg).Result.ExitCode; got != ExitCodeCancelled {
+			t.Errorf("exit code = %d, want the run cancelled", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the watch run kept going after the watch stopped")
+	}
+}
+
+// useConfigFile points loads and saves at path for the rest of the test
+func useConfigFile(t *testing.T, path string) {
+	t.Helper()
+	config.SetConfigPath(path)
+	t.Cleanup(func() { config.SetConfigPath("") })
+}
+
+func TestSaveCommandsKeepsUnsavedChanges(t *testing.T) {
+	// A directory where the commands file should be makes every write fail
+	path := filepath.Join(t.TempDir(), "commands.json")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	useConfigFile(t, path)
+
+	m := testModel(model.Command{ID: "1", Name: "list", Command: "ls"})
+	m.AllCommands[0].Name = "list files"
+	saveCommands(&m)
+	if !m.UnsavedChanges {
+		t.Fatal("UnsavedChanges = false after a failed save")
+	}
+	if m.Error == "" {
+		t.Error("no error shown for the failed save")
+	}
+	if m.AllCommands[0].Name != "list files" {
+		t.Error("the edit was dropped from memory")
+	}
+
+	// Once the disk recovers, ctrl+s writes the changes
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	m = press(m, "ctrl+s")
+	if m.UnsavedChanges {
+		t.Fatalf("UnsavedChanges = true after retrying; error: %s", m.Error)
+	}
+	commands, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != 1 || commands[0].Name != "list files" {
+		t.Errorf("saved commands = %+v, want the edited command", commands)
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	disk := model.Command{Name: "Disk Space", Command: "df -h", Category: "System", Tags: []string{"storage"}}
+	tests := []struct {
+		query string
+		match bool
+	}{
+		{"disk", true},
+		{"DISK", true},
+		{"dsk spc", true},
+		{"df", true},
+		{"storage", true},
+		{"system", true},
+		{"disk memory", false},
+		{"xyz", false},
+	}
+	for _, tt := range tests {
+		if got := matchScore(disk, tt.query); (got > 0) != tt.match {
+			t.Errorf("matchScore(%q) = %d, want a match: %v", tt.query, got, tt.match)
+		}
+	}
+
+	// A match in the name outranks one in the description
+	byName := model.Command{Name: "deploy", Command: "make"}
+	byDescription := model.Command{Name: "build", Command: "make", Description: "before deploy"}
+	if matchScore(byName, "deploy") <= matchScore(byDescription, "deploy") {
+		t.Error("a name match doesn't outrank a description match")
+	}
+	// A contiguous match outranks a scattered one
+	if matchScore(model.Command{Name: "disk"}, "dsk") >= matchScore(model.Command{Name: "dsk"}, "dsk") {
+		t.Error("a scattered match doesn't rank below a contiguous one")
+	}
+}
+
+func TestFilterRanksBestMatchFirst(t *testing.T) {
+	m := testModel(
+		model.Command{ID: "1", Name: "Docker Stop", Command: "docker stop", Category: "Docker"},
+		model.Command{ID: "2", Name: "Disk Space", Command: "df -h", Category: "System"},
+		model.Command{ID: "3", Name: "Disk Usage", Command: "du -sh", Category: "Docker"},
+	)
+	m.FilterText = "dsk spc"
+	applyFilter(&m)
+	if len(m.VisibleCommands) == 0 || m.VisibleCommands[0].ID != "2" {
+		t.Fatalf("visible = %+v, want Disk Space first", m.VisibleCommands)
+	}
+
+	// The category is a hard filter applied before scoring
+	m.ActiveCategory = "Docker"
+	m.FilterText = "disk"
+	applyFilter(&m)
+	if len(m.VisibleCommands) != 1 || m.VisibleCommands[0].ID != "3" {
+		t.Errorf("visible = %+v, want only Disk Usage", m.VisibleCommands)
+	}
+}
+
+func TestBatchAsksBeforeRunningConfirmedCommands(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useConfigFile(t, filepath.Join(t.TempDir(), "commands.json"))
+	m := testModel(
+		model.Command{ID: "1", Name: "list", Command: "true"},
+		model.Command{ID: "2", Name: "wipe", Command: "true", Confirm: true},
+	)
+	m.Marked = map[string]bool{"1": true, "2": true}
+
+	for _, background := range []bool{false, true} {
+		m.RunInBackground = background
+		m = press(m, "R")
+		if m.CurrentMode != model.ModeConfirm || !m.ConfirmBatch {
+			t.Fatalf("background %v: batch started without asking", background)
+		}
+		m = press(m, "n")
+		if m.CurrentMode != model.ModeNormal || m.ConfirmBatch || m.Executing {
+			t.Fatalf("background %v: cancelling left mode %v, executing %v", background, m.CurrentMode, m.Executing)
+		}
+	}
+
+	m.RunInBackground = false
+	m = press(m, "R")
+	m = press(m, "y")
+	if !m.Executing || m.BatchTotal != 2 {
+		t.Errorf("confirmed batch not running: executing %v, total %d", m.Executing, m.BatchTotal)
+	}
+	if m.ExecutionCancel != nil {
+		m = cancelExecution(m)
+	}
+}
+
+func TestBatchWithConfirmBeforeRun(t *testing.T) {
+	m := testModel(model.Command{ID: "1", Name: "list", Command: "true"})
+	m.Marked = map[string]bool{"1": true}
+	m.Settings.ConfirmBeforeRun = true
+	m = press(m, "R")
+	if !m.ConfirmBatch {
+		t.Error("confirm_before_run doesn't apply to batches")
+	}
+}
+
+func TestFilterKeepsSelectionValid(t *testing.T) {
+	m := testModel(
+		model.Command{ID: "1", Name: "deploy web", Command: "true"},
+		model.Command{ID: "2", Name: "deploy api", Command: "true"},
+		model.Command{ID: "3", Name: "status", Command: "true"},
+	)
+	m.SelectedIndex = 2
+
+	// valid reports whether SelectedIndex points at a visible command, or is
+	// 0 when nothing is visible
+	valid := func(m model.Model) bool {
+		if len(m.VisibleCommands) == 0 {
+			return m.SelectedIndex == 0
+		}
+		return m.SelectedIndex >= 0 && m.SelectedIndex < len(m.VisibleCommands)
+	}
+
+	m = press(m, "f")
+	for _, step := range []struct {
+		key     string
+		visible int
+	}{
+		{"d", 2},
+		{"e", 2},
+		{"w", 1},         // deploy web
+		{"zz", 0},        // nothing
+		{"backspace", 0}, // "dewz" still matches nothing
+		{"backspace", 1},
+		{"backspace", 2},
+		{"backspace", 2},
+		{"backspace", 3},
+	} {
+		m = press(m, step.key)
+		if len(m.VisibleCommands) != step.visible {
+			t.Fatalf("after %q: %d visible, want %d", step.key, len(m.VisibleCommands), step.visible)
+		}
+		if !valid(m) {
+			t.Fatalf("after %q: SelectedIndex %d with %d visible", step.key, m.SelectedIndex, len(m.VisibleCommands))
+		}
+	}
+	m = press(m, "enter")
+	if !valid(m) {
+		t.Errorf("after applying: SelectedIndex %d with %d visible", m.SelectedIndex, len(m.VisibleCommands))
+	}
+}
+
+func TestSavingFormCommandsBackToBackGivesDistinctIDs(t *testing.T) {
+	useConfigFile(t, filepath.Join(t.TempDir(), "commands.json"))
+	m := testModel()
+
+	for _, name := range []string{"first", "second"} {
+		m = press(m, "n")
+		m.FormCommand.Name = name
+		m.FormCommand.Command = "true"
+		m = press(m, "y")
+		if m.ShowForm {
+			t.Fatalf("saving %q failed: %s", name, m.Error)
+		}
+	}
+
+	if len(m.AllCommands) != 2 {
+		t.Fatalf("%d commands, want 2", len(m.AllCommands))
+	}
+	if m.AllCommands[0].ID == m.AllCommands[1].ID {
+		t.Fatalf("both commands got ID %s", m.AllCommands[0].ID)
+	}
+
+	// Deleting by ID removes only the selected command
+	m.SelectedIndex = 0
+	kept := m.VisibleCommands[1].ID
+	m = press(m, "d")
+	saved, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].ID != kept {
+		t.Errorf("saved commands = %+v, want only %s", saved, kept)
+	}
+}
+
+func TestStreamPollReadsEverythingWritten(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useConfigFile(t, filepath.Join(t.TempDir(), "commands.json"))
+	log, err := os.CreateTemp(t.TempDir(), "stream-*.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	command := model.Command{ID: "1", Name: "chatty", Command: "yes"}
+	m := testModel(command)
+	m.Executing = true
+	m.ExecutingCommand = &command
+	m.ExecutionLogPath = log.Name()
+	m.StreamedOutput = model.NewOutputBuffer(0)
+
+	// Each tick the command writes far more than 64KB
+	line := strings.Repeat("x", 99) + "\n"
+	written := 0
+	var reader *os.File
+	for tick := 1; tick <= 3; tick++ {
+		chunk := strings.Repeat(line, 1000*tick)
+		if _, err := log.WriteString(chunk); err != nil {
+			t.Fatal(err)
+		}
+		written += len(chunk)
+
+		m, _ = handleStreamPoll(m)
+		if m.ExecutionLogOffset != int64(written) {
+			t.Fatalf("tick %d: read up to %d, want %d", tick, m.ExecutionLogOffset, written)
+		}
+		if got := len(m.StreamedOutput.String()); got != written {
+			t.Fatalf("tick %d: %d bytes of output, want %d", tick, got, written)
+		}
+		// The log is opened once and kept open
+		if reader == nil {
+			reader = m.ExecutionLogFile
+		} else if m.ExecutionLogFile != reader {
+			t.Fatalf("tick %d: the log was reopened", tick)
+		}
+	}
+
+	m, _ = Update(CommandResultMsg{Result: Result{Command: command}}, m)
+	if m.ExecutionLogFile != nil {
+		t.Error("the log is still held after the command finished")
+	}
+	if err := reader.Close(); err == nil {
+		t.Error("the log was not closed when the command finished")
+	}
+}
+
+func TestCommandResultKeepsOnlyTheLastLines(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useConfigFile(t, filepath.Join(t.TempDir(), "commands.json"))
+	log, err := os.CreateTemp(t.TempDir(), "stream-*.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	command := model.Command{ID: "1", Name: "chatty", Command: "seq 23"}
+	m := testModel(command)
+	m.Settings.OutputMaxLines = 5
+	m.Executing = true
+	m.ExecutingCommand = &command
+	m.ExecutionLogPath = log.Name()
+	m.StreamedOutput = model.NewOutputBuffer(m.Settings.OutputMaxLines)
+
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(log, "line %d\n", i)
+	}
+	m, _ = handleStreamPoll(m)
+	// The command prints a few more lines before the next poll
+	for i := 21; i <= 23; i++ {
+		fmt.Fprintf(log, "line %d\n", i)
+	}
+	m, _ = Update(CommandResultMsg{Result: Result{Command: command}}, m)
+
+	if !strings.Contains(m.ExecutionOutput, "line 23") {
+		t.Errorf("the lines written after the last poll are missing:\n%s", m.ExecutionOutput)
+	}
+	if strings.Contains(m.ExecutionOutput, "line 18\n") {
+		t.Errorf("more than output_max_lines lines are kept:\n%s", m.ExecutionOutput)
+	}
+	if !strings.Contains(m.ExecutionOutput, "(18 earlier lines dropped") {
+		t.Errorf("the dropped lines are not noted:\n%s", m.ExecutionOutput)
+	}
+}
+
+func TestLeavingTheOutputRemovesTheTempLog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useConfigFile(t, filepath.Join(t.TempDir(), "commands.json"))
+	dir := t.TempDir()
+	tempLog := filepath.Join(dir, "go-recipe-stream-1.log")
+	backgroundLog := filepath.Join(dir, "build.log")
+	for _, path := range []string{tempLog, backgroundLog} {
+		if err := os.WriteFile(path, []byte("done\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	command := model.Command{ID: "1", Name: "build", Command: "make"}
+	m := testModel(command)
+	m.Executing = true
+	m.ExecutingCommand = &command
+	m.ExecutionLogPath = tempLog
+	m.ExecutionTempLog = tempLog
+	m, _ = Update(CommandResultMsg{Result: Result{Command: command}}, m)
+	// The pager may still open the log of a finished run
+	if _, err := os.Stat(tempLog); err != nil {
+		t.Fatalf("the log was removed while its output is shown: %v", err)
+	}
+	m = press(m, "esc")
+	if _, err := os.Stat(tempLog); !os.IsNotExist(err) {
+		t.Error("the temp log is left behind once the output is closed")
+	}
+
+	m, _ = viewLog(model.LogFile{Name: "build", Path: backgroundLog}, m)
+	m = press(m, "esc")
+	if _, err := os.Stat(backgroundLog); err != nil {
+		t.Errorf("closing a background log removed it: %v", err)
+	}
+}
+
+func TestPTYScreenIsDrawnAsATerminalWould(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	useConfigFile(t, filepath.Join(t.TempDir(), "commands.json"))
+	log, err := os.CreateTemp(t.TempDir(), "go-recipe-pty-*.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	command := model.Command{ID: "1", Name: "top", Command: "top", Interactive: true}
+	m := testModel(command)
+	m.Executing = true
+	m.ExecutingCommand = &command
+	m.ExecutionLogPath = log.Name()
+	cols, rows := ptySize(m)
+	m.Screen = model.NewScreen(cols, rows, 0)
+
+	// A full-screen program redraws its screen in place
+	_, _ = log.WriteString("$ top\r\n\x1b[?1049h\x1b[H\x1b[2Jload 1.0\x1b[1;6H2.5")
+	m, _ = handleStreamPoll(m)
+	lines := strings.Split(m.ExecutionOutput, "\n")
+	if len(lines) != rows {
+		t.Fatalf("the view shows %d lines, want the %d rows of the screen", len(lines), rows)
+	}
+	if got := StripANSI(lines[0]); !strings.HasPrefix(got, "load 2.5") {
+		t.Errorf("first row = %q, want the redrawn %q", got, "load 2.5")
+	}
+
+	// Leaving the alternate screen on exit leaves what was there before
+	_, _ = log.WriteString("\x1b[?1049l$ ")
+	m, _ = Update(CommandResultMsg{Result: Result{Command: command}}, m)
+	if m.Screen != nil {
+		t.Error("the screen is kept after the command finished")
+	}
+	if !strings.Contains(m.ExecutionOutput, "$ top\n$") || strings.Contains(m.ExecutionOutput, "load") {
+		t.Errorf("output = %q, want only the main screen", m.ExecutionOutput)
+	}
+}
+
+func TestForegroundLogIsMaskedAsItIsWritten(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses echo")
+	}
+	command := model.Command{ID: "1", Name: "login", Command: "echo token=s3cret", Redact: []string{"s3cret"}}
+	m, run := startForeground(command, testModel(command))
+	t.Cleanup(func() { os.Remove(m.ExecutionLogPath) })
+
+	// The log is what is left behind when quitting mid-run or paging
+	run()
+	content, err := os.ReadFile(m.ExecutionLogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(content); strings.Contains(got, "s3cret") || !strings.Contains(got, "token=****") {
+		t.Errorf("log = %q, want the secret masked", got)
+	}
+}
+
+func TestValidateCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []model.Command
+		wantErr  string
+	}{
+		{"valid", []model.Command{
+			{ID: "1", Name: "a", Command: "ls", Aliases: []string{"l"}},
+			{ID: "2", Name: "a", Command: "ls -a"},
+			{Name: "b", Command: "pwd"},
+		}, ""},
+		{"missing command line", []model.Command{{ID: "1", Name: "a"}}, "needs both"},
+		{"repeated ID", []model.Command{
+			{ID: "1", Name: "a", Command: "ls"},
+			{ID: "1", Name: "b", Command: "pwd"},
+		}, "ID 1"},
+		{"repeated alias", []model.Command{
+			{ID: "1", Name: "a", Command: "ls", Aliases: []string{"up"}},
+			{ID: "2", Name: "b", Command: "pwd", Aliases: []string{"UP"}},
+		}, `alias "UP"`},
+		{"alias repeated with accents", []model.Command{
+			{ID: "1", Name: "a", Command: "ls", Aliases: []string{"cafe"}},
+			{ID: "2", Name: "b", Command: "pwd", Aliases: []string{"Café"}},
+		}, `alias "Café"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCommands(tt.commands)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateCommands() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateCommands() = %v, want an error mentioning %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFillPlaceholders(t *testing.T) {
+	command := model.Command{Name: "ping", Command: "ping -c {{count}} {{ host }} # {{host}}"}
+
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    string
+		wantErr string
+	}{
+		{"all filled in", map[string]string{"host": "example.com", "count": "3"}, "ping -c 3 example.com # example.com", ""},
+		{"missing value", map[string]string{"host": "example.com"}, "", "count"},
+		{"unknown name", map[string]string{"host": "example.com", "count": "3", "hots": "x"}, "", "{{hots}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FillPlaceholders(command, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("FillPlaceholders() = %v, want an error mentioning %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Command != tt.want {
+				t.Errorf("FillPlaceholders() = %q, want %q", got.Command, tt.want)
+			}
+		})
+	}
+}
+
+func TestWarningStaysUntilDismissed(t *testing.T) {
+	m := testModel(model.Command{ID: "1", Name: "a", Command: "ls"}, model.Command{ID: "2", Name: "b", Command: "pwd"})
+	m.Warning = "commands file recovered"
+
+	m = press(m, "j")
+	m = press(m, "k")
+	if m.Warning == "" {
+		t.Fatal("warning cleared by moving around the list")
+	}
+	m = press(m, "esc")
+	if m.Warning != "" {
+		t.Errorf("warning = %q after esc, want it dismissed", m.Warning)
+	}
+}
+
+func TestInvalidEditIsBackedUpBeforeTheNextSave(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "commands.json")
+	useConfigFile(t, path)
+	edited := []byte(`[{"id": "1", "name": "build"}]`)
+	if err := os.WriteFile(path, edited, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := testModel(model.Command{ID: "1", Name: "build", Command: "make"})
+	m, _ = Update(ConfigEditedMsg{}, m)
+	if len(m.AllCommands) != 1 || m.AllCommands[0].Command != "make" {
+		t.Fatalf("commands = %+v, want those loaded before", m.AllCommands)
+	}
+	backups, _ := filepath.Glob(path + ".bak.*")
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v, want the invalid edit copied once", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != string(edited) {
+		t.Errorf("backup holds %q, want the edit", data)
+	}
+
+	// The warning outlives the next keypress and the save that overwrites
+	// the file
+	m = press(m, "j")
+	saveCommands(&m)
+	if !strings.Contains(m.Warning, backups[0]) {
+		t.Errorf("warning = %q, want it to point at %s", m.Warning, backups[0])
+	}
+}
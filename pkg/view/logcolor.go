@@ -0,0 +1,47 @@
+package view
+
+import (
+	"regexp"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultLogRules color common log level markers
+var defaultLogRules = []model.LogRule{
+	{Pattern: `\b(ERROR|FATAL|PANIC)\b`, Color: "#FF5555"},
+	{Pattern: `\bWARN(ING)?\b`, Color: "#FFCC00"},
+}
+
+// compiledPatterns caches compiled log rule patterns; invalid patterns map to nil
+var compiledPatterns = map[string]*regexp.Regexp{}
+
+// compilePattern returns the cached regexp for pattern, or nil if it is invalid
+func compilePattern(pattern string) *regexp.Regexp {
+	if re, ok := compiledPatterns[pattern]; ok {
+		return re
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	compiledPatterns[pattern] = re
+	return re
+}
+
+// colorizeLogLine styles a line with the first matching rule. User rules are
+// checked before the defaults so they can override them.
+func colorizeLogLine(line string, rules []model.LogRule) string {
+	if noColor {
+		return line
+	}
+	for _, set := range [][]model.LogRule{rules, defaultLogRules} {
+		for _, rule := range set {
+			re := compilePattern(rule.Pattern)
+			if re != nil && re.MatchString(line) {
+				return lipgloss.NewStyle().Foreground(lipgloss.Color(rule.Color)).Render(line)
+			}
+		}
+	}
+	return line
+}
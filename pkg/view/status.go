@@ -0,0 +1,98 @@
+package view
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Result styles are built from the active theme by SetTheme
+var (
+	exitSuccessStyle lipgloss.Style
+	exitFailureStyle lipgloss.Style
+	errorBlockStyle  lipgloss.Style
+	stderrStyle      lipgloss.Style
+)
+
+// resultLineStyles finds the lines of a finished result's header and error
+// block (see update.FormatOutput) and returns their styles by line index: the
+// exit code in green or red, the stderr section (see
+// update.FormatOutputSeparated) in its own color and the error block
+// highlighted. Batch output holds several results, so every header is styled.
+func resultLineStyles(lines []string) map[int]lipgloss.Style {
+	styles := map[int]lipgloss.Style{}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "Exit Code: ") && headerEndsAt(lines, i+1):
+			fields := strings.Fields(strings.TrimPrefix(line, "Exit Code: "))
+			if len(fields) == 0 {
+				continue
+			}
+			if code, err := strconv.Atoi(fields[0]); err == nil && code == 0 {
+				styles[i] = exitSuccessStyle
+			} else {
+				styles[i] = exitFailureStyle
+			}
+		case line == "--- Stderr ---":
+			// The stderr section runs up to the error block or the end of this result
+			for ; i < len(lines) && lines[i] != "--- Error ---" && !strings.HasPrefix(lines[i], "═"); i++ {
+				styles[i] = stderrStyle
+			}
+			i--
+		case line == "--- Error ---":
+			// The error block runs to the end of this result
+			for ; i < len(lines) && !strings.HasPrefix(lines[i], "═"); i++ {
+				styles[i] = errorBlockStyle
+			}
+		}
+	}
+	return styles
+}
+
+// headerEndsAt reports whether the header lines after the exit code, starting
+// at i, lead to the output marker
+func headerEndsAt(lines []string, i int) bool {
+	if i < len(lines) && strings.HasPrefix(lines[i], "Attempts: ") {
+		i++
+	}
+	return i+1 < len(lines) && lines[i] == "" && lines[i+1] == "--- Output ---"
+}
+
+// lastRunStatus renders a command's ✓ or ✗ for the exit code of its last run
+// and how long ago it ran, or nothing for a command that never ran
+func lastRunStatus(cmd model.Command, now time.Time) string {
+	if cmd.LastRun.IsZero() {
+		return ""
+	}
+	status := " "
+	if cmd.LastExit != nil {
+		if *cmd.LastExit == 0 {
+			status += exitSuccessStyle.Render("✓") + " "
+		} else {
+			status += exitFailureStyle.Render("✗") + " "
+		}
+	}
+	return status + mutedStyle.Render("last run "+relativeTime(cmd.LastRun, now))
+}
+
+// relativeTime describes how long before now t was, e.g. "5m ago", switching
+// to the date after a month
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+	return "on " + t.Local().Format("Jan 2, 2006")
+}
@@ -2,72 +2,193 @@ package view
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/Tomlord1122/go-recipe/pkg/model"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Styles are built from the active theme by SetTheme
 var (
-	// Define styles
+	titleStyle            lipgloss.Style
+	subtitleStyle         lipgloss.Style
+	selectedItemStyle     lipgloss.Style
+	itemStyle             lipgloss.Style
+	commandStyle          lipgloss.Style
+	descriptionStyle      lipgloss.Style
+	categoryStyle         lipgloss.Style
+	selectedCategoryStyle lipgloss.Style
+	errorStyle            lipgloss.Style
+	infoStyle             lipgloss.Style
+	outputStyle           lipgloss.Style
+	helpStyle             lipgloss.Style
+	selectionStyle        lipgloss.Style
+	matchStyle            lipgloss.Style
+	confirmStyle          lipgloss.Style
+	cursorStyle           lipgloss.Style
+	editingStyle          lipgloss.Style
+	mutedStyle            lipgloss.Style
+	scrollStyle           lipgloss.Style
+)
+
+// noColor is set when NO_COLOR asks for output without colors
+var noColor bool
+
+func init() {
+	SetTheme(model.DarkTheme())
+}
+
+// UseTheme picks the styles for the current terminal. NO_COLOR (see
+// https://no-color.org) wins and turns colors off entirely; otherwise an
+// explicitly configured theme is used as is, and without one the light theme
+// is chosen when the terminal reports a light background.
+func UseTheme(theme model.Theme, explicit bool) {
+	switch {
+	case os.Getenv("NO_COLOR") != "":
+		noColor = true
+		SetTheme(model.Theme{})
+		// Without colors, selections are shown in reverse video
+		selectedItemStyle = selectedItemStyle.Reverse(true)
+		selectedCategoryStyle = selectedCategoryStyle.Reverse(true)
+		selectionStyle = selectionStyle.Reverse(true)
+		cursorStyle = cursorStyle.Reverse(true)
+		editingStyle = editingStyle.Reverse(true)
+		errorBlockStyle = errorBlockStyle.Reverse(true)
+	case !explicit && !lipgloss.HasDarkBackground():
+		SetTheme(model.LightTheme())
+	default:
+		SetTheme(theme)
+	}
+}
+
+// SetTheme rebuilds the styles from the given theme
+func SetTheme(t model.Theme) {
 	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Background(lipgloss.Color("#7D56F4")).
-			Padding(0, 1).
-			Width(80)
+		Bold(true).
+		Foreground(lipgloss.Color(t.Title)).
+		Background(lipgloss.Color(t.TitleBackground)).
+		Padding(0, 1).
+		Width(80)
 
 	subtitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Background(lipgloss.Color("#383838")).
-			Padding(0, 1)
+		Foreground(lipgloss.Color(t.Subtitle)).
+		Background(lipgloss.Color(t.SubtitleBackground)).
+		Padding(0, 1)
 
 	selectedItemStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFFFFF")).
-				Background(lipgloss.Color("#7D56F4")).
-				Bold(true).
-				Padding(0, 1)
+		Foreground(lipgloss.Color(t.Selected)).
+		Background(lipgloss.Color(t.SelectedBackground)).
+		Bold(true).
+		Padding(0, 1)
 
 	itemStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#DDDDDD")).
-			Padding(0, 1)
+		Foreground(lipgloss.Color(t.Item)).
+		Padding(0, 1)
 
 	commandStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#36A9E0")).
-			Padding(0, 1)
+		Foreground(lipgloss.Color(t.Command)).
+		Padding(0, 1)
 
 	descriptionStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#4CAF50")).
-				Padding(0, 1)
+		Foreground(lipgloss.Color(t.Description)).
+		Padding(0, 1)
 
 	categoryStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7D56F4")).
-			Bold(true).
-			Padding(0, 1)
+		Foreground(lipgloss.Color(t.Accent)).
+		Bold(true).
+		Padding(0, 1)
 
 	selectedCategoryStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFFFFF")).
-				Background(lipgloss.Color("#7D56F4")).
-				Bold(true).
-				Padding(0, 1)
+		Foreground(lipgloss.Color(t.Selected)).
+		Background(lipgloss.Color(t.SelectedBackground)).
+		Bold(true).
+		Padding(0, 1)
 
 	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF0000")).
-			Bold(true).
-			Padding(0, 1)
+		Foreground(lipgloss.Color(t.Error)).
+		Bold(true).
+		Padding(0, 1)
+
+	infoStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Info)).
+		Padding(0, 1)
 
 	outputStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#00FF00")).
-			Background(lipgloss.Color("#222222")).
-			Padding(1, 2)
+		Foreground(lipgloss.Color(t.Output)).
+		Background(lipgloss.Color(t.OutputBackground)).
+		Padding(1, 2)
 
 	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#BBBBBB")).
-			Padding(1, 2)
-)
+		Foreground(lipgloss.Color(t.Help)).
+		Padding(1, 2)
+
+	selectionStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Selected)).
+		Background(lipgloss.Color(t.Highlight))
+
+	matchStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Match)).
+		Underline(true)
+
+	confirmStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Text)).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(t.Confirm)).
+		Padding(0, 1)
+
+	cursorStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Selected)).
+		Background(lipgloss.Color(t.Cursor))
+
+	editingStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Selected)).
+		Background(lipgloss.Color(t.Editing)).
+		Bold(true)
+
+	mutedStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Muted))
+
+	scrollStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Accent)).
+		Bold(true)
+
+	exitSuccessStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Success)).
+		Bold(true)
+
+	exitFailureStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Failure)).
+		Bold(true)
+
+	errorBlockStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Selected)).
+		Background(lipgloss.Color(t.ErrorBlock))
+
+	stderrStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Stderr))
+}
+
+// statusMessage renders the current error in the error color and the current
+// status message in the info color, one per line
+func statusMessage(m model.Model) string {
+	var lines []string
+	if m.Error != "" {
+		lines = append(lines, errorStyle.Render(m.Error))
+	}
+	if m.Info != "" {
+		lines = append(lines, infoStyle.Render(m.Info))
+	}
+	return strings.Join(lines, "\n")
+}
 
 // Render renders the UI based on the current model state
 func Render(m model.Model) string {
+	if m.CurrentMode == model.ModePalette {
+		return renderPalette(m)
+	}
+
 	if m.Executing {
 		return renderExecution(m)
 	}
@@ -80,6 +201,18 @@ func Render(m model.Model) string {
 		return renderForm(m)
 	}
 
+	if m.ShowLogs {
+		return renderLogs(m)
+	}
+
+	if m.ShowTasks {
+		return renderTasks(m)
+	}
+
+	if m.ShowCategories {
+		return renderCategories(m)
+	}
+
 	return renderMain(m)
 }
 
@@ -89,21 +222,47 @@ func renderMain(m model.Model) string {
 
 	// Render title
 	sb.WriteString(titleStyle.Render("go-recipe - command manager"))
+	if m.SortMode != model.SortConfig {
+		sb.WriteString(helpStyle.Render("  Sort: " + m.SortMode.String()))
+	}
+	running := 0
+	for _, task := range m.Tasks {
+		if !task.Done {
+			running++
+		}
+	}
+	if running > 0 {
+		sb.WriteString(categoryStyle.Render(fmt.Sprintf("  %d running in background (T)", running)))
+	}
 	sb.WriteString("\n\n")
 
 	// Render categories
 	sb.WriteString("Categories: ")
 	for i, category := range m.Categories {
-		if category == m.ActiveCategory {
-			sb.WriteString(selectedCategoryStyle.Render(category))
+		label := category
+		if icon := m.Settings.CategoryIcons[category]; icon != "" {
+			label = icon + " " + category
+		}
+		if category == m.ActiveCategory || (m.ActiveCategory == "" && category == "All") {
+			sb.WriteString(selectedCategoryStyle.Render(label))
 		} else {
-			sb.WriteString(categoryStyle.Render(category))
+			sb.WriteString(categoryStyle.Render(label))
 		}
 		if i < len(m.Categories)-1 {
 			sb.WriteString(" | ")
 		}
 	}
-	sb.WriteString("\n\n")
+	sb.WriteString("\n")
+	if len(m.ActiveTags) > 0 {
+		match := "any"
+		if m.MatchAllTags {
+			match = "all"
+		}
+		sb.WriteString(fmt.Sprintf("Tags (%s): ", match))
+		sb.WriteString(selectedCategoryStyle.Render(strings.Join(m.ActiveTags, ", ")))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
 
 	// Render filter information
 	filterTextStyle := commandStyle
@@ -111,10 +270,7 @@ func renderMain(m model.Model) string {
 		filterTextStyle = selectedItemStyle
 		sb.WriteString("Filter: ")
 		sb.WriteString(filterTextStyle.Render(m.InputBuffer))
-		sb.WriteString(lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#FF00FF")).
-			Render("_"))
+		sb.WriteString(cursorStyle.Render("_"))
 		sb.WriteString("\n\n")
 	} else if m.FilterText != "" {
 		sb.WriteString(fmt.Sprintf("Filter: %s", filterTextStyle.Render(m.FilterText)))
@@ -125,24 +281,95 @@ func renderMain(m model.Model) string {
 	if len(m.VisibleCommands) == 0 {
 		sb.WriteString(itemStyle.Render("No commands found."))
 	} else {
-		for i, cmd := range m.VisibleCommands {
+		// Show only the window of commands that fits on screen, keeping the
+		// selection inside it
+		startRow, endRow := m.ListWindow(m.ListRows())
+		moreStyle := helpStyle.UnsetPadding()
+		// Grouped rows are indented beneath their category header
+		indent := ""
+		if m.GroupedList() {
+			indent = "  "
+		}
+
+		if startRow > 0 {
+			sb.WriteString(moreStyle.Render(fmt.Sprintf("  ↑ %d more", startRow)))
+			sb.WriteString("\n")
+		}
+		for i := startRow; i < endRow; i++ {
+			cmd := m.VisibleCommands[i]
+			if m.StartsGroup(i, startRow) {
+				sb.WriteString(categoryHeader(m, cmd.Category))
+				sb.WriteString("\n")
+			}
+			prefix := indent
+			if m.Marked[cmd.ID] {
+				prefix += "● "
+			}
+			if cmd.Favorite {
+				prefix += "★ "
+			}
+			if cmd.Protected {
+				prefix += "🔒 "
+			}
+			if slot := m.Settings.SlotFor(cmd.ID); slot != 0 {
+				prefix += fmt.Sprintf("[%d] ", slot)
+			}
+			rowStyle := itemStyle
+			if i == m.SelectedIndex {
+				rowStyle = selectedItemStyle
+			}
+			// The header already names the category of grouped rows
+			suffix := fmt.Sprintf(" (%s)", cmd.Category)
+			if m.GroupedList() {
+				suffix = ""
+			}
+			label := prefix + cmd.Name + suffix
+			if m.FilterText != "" {
+				// Pick out the characters the filter matched, keeping their casing
+				plain := rowStyle.UnsetPadding()
+				label = plain.Render(prefix) + highlightMatches(cmd.Name, m.FilterText, plain) +
+					plain.Render(suffix)
+			}
 			if i == m.SelectedIndex {
-				sb.WriteString(selectedItemStyle.Render(fmt.Sprintf("%s (%s)", cmd.Name, cmd.Category)))
+				sb.WriteString(selectedItemStyle.Render(label))
+				sb.WriteString(lastRunStatus(cmd, time.Now()))
 				sb.WriteString("\n")
-				sb.WriteString(commandStyle.Render(fmt.Sprintf("  Command: %s", cmd.Command)))
+				commandText := firstLine(cmd.Command)
+				if m.FilterText != "" {
+					plain := commandStyle.UnsetPadding()
+					commandText = highlightMatches(commandText, m.FilterText, plain)
+				}
+				sb.WriteString(commandStyle.Render(indent + "  Command: " + commandText))
 				sb.WriteString("\n")
-				sb.WriteString(descriptionStyle.Render(fmt.Sprintf("  Description: %s", cmd.Description)))
+				sb.WriteString(descriptionStyle.Render(fmt.Sprintf("%s  Description: %s", indent, cmd.Description)))
 			} else {
-				sb.WriteString(itemStyle.Render(fmt.Sprintf("%s (%s)", cmd.Name, cmd.Category)))
+				sb.WriteString(itemStyle.Render(label))
+				sb.WriteString(lastRunStatus(cmd, time.Now()))
+				if m.RowLines(i) > 1 {
+					sb.WriteString("\n")
+					sb.WriteString(mutedStyle.Render(fmt.Sprintf("%s   %s", indent, cmd.Description)))
+				}
 			}
 			sb.WriteString("\n")
 		}
+		if endRow < len(m.VisibleCommands) {
+			sb.WriteString(moreStyle.Render(fmt.Sprintf("  ↓ %d more", len(m.VisibleCommands)-endRow)))
+			sb.WriteString("\n")
+		}
 	}
 
-	// Render error
-	if m.Error != "" {
+	// Render the error or status message
+	if message := statusMessage(m); message != "" {
+		sb.WriteString("\n")
+		sb.WriteString(message)
+	}
+	if m.Warning != "" {
+		sb.WriteString("\n")
+		sb.WriteString(errorStyle.Render("⚠ " + m.Warning + " (esc to dismiss)"))
+	}
+	if m.UnsavedChanges {
 		sb.WriteString("\n")
-		sb.WriteString(errorStyle.Render(m.Error))
+		sb.WriteString(errorStyle.Render("⚠ Changes not saved to disk (ctrl+s to retry)"))
 	}
 
 	// Render help shortcuts
@@ -151,6 +378,60 @@ func renderMain(m model.Model) string {
 	// Show different help text based on current mode
 	if m.CurrentMode == model.ModeFilterInput {
 		sb.WriteString(helpStyle.Render("Enter: Apply Filter  |  Esc: Cancel  |  Ctrl+u: Clear Filter"))
+	} else if m.CurrentMode == model.ModeConfirm && m.ConfirmBatch {
+		sb.WriteString(confirmStyle.Render(batchConfirmation(m)))
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render("y: Run the batch  |  n/Esc: Cancel"))
+	} else if m.CurrentMode == model.ModeConfirm && m.ConfirmCommand != nil {
+		sb.WriteString(confirmStyle.Render(fmt.Sprintf("Run %q?\n\n$ %s", m.ConfirmCommand.Name, m.ConfirmCommand.Command)))
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render("y: Run  |  n/Esc: Cancel"))
+	} else if m.CurrentMode == model.ModeWatchInterval && m.WatchCommand != nil {
+		sb.WriteString(fmt.Sprintf("Watch %q every: ", m.WatchCommand.Name))
+		sb.WriteString(selectedItemStyle.Render(m.InputBuffer))
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render("Seconds or a duration like 500ms/1m  |  Enter: Start  |  Esc: Cancel"))
+	} else if m.CurrentMode == model.ModePlaceholder && m.PlaceholderCommand != nil {
+		step := len(m.PlaceholderValues)
+		sb.WriteString(fmt.Sprintf("%s (%d/%d) %s: ", m.PlaceholderCommand.Name, step+1, len(m.PlaceholderNames), m.PlaceholderNames[step]))
+		sb.WriteString(selectedItemStyle.Render(m.InputBuffer))
+		sb.WriteString("\n")
+		history := m.Settings.PlaceholderHistory[m.PlaceholderNames[step]]
+		for i, value := range history {
+			if i == m.PlaceholderPick {
+				sb.WriteString(selectedItemStyle.Render(value))
+			} else {
+				sb.WriteString(itemStyle.Render(value))
+			}
+			sb.WriteString("\n")
+		}
+		if len(history) > 0 {
+			sb.WriteString(helpStyle.Render("↑/↓: Recent values  |  Enter: Next  |  Ctrl+u: Clear  |  Esc: Cancel"))
+		} else {
+			sb.WriteString(helpStyle.Render("Enter: Next  |  Ctrl+u: Clear  |  Esc: Cancel"))
+		}
+	} else if m.CurrentMode == model.ModeTagPicker {
+		if len(m.TagOptions) == 0 {
+			sb.WriteString(itemStyle.Render("No tags yet. Add tags to commands in the form."))
+			sb.WriteString("\n")
+		}
+		for i, tag := range m.TagOptions {
+			box := "[ ] "
+			for _, active := range m.ActiveTags {
+				if active == tag {
+					box = "[x] "
+				}
+			}
+			if i == m.TagIndex {
+				sb.WriteString(selectedItemStyle.Render(box + tag))
+			} else {
+				sb.WriteString(itemStyle.Render(box + tag))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString(helpStyle.Render("↑/↓: Navigate  |  Space: Toggle  |  a: Any/All  |  x: Clear  |  Enter/Esc: Done"))
+	} else if m.CurrentMode == model.ModePinSlot {
+		sb.WriteString(helpStyle.Render("1-9: Pin to slot  |  0: Unpin  |  Esc: Cancel"))
 	} else {
 		sb.WriteString(helpStyle.Render("↑/↓: Navigate  |  Enter: Execute  |  n: New  |  f: Filter  |  c: Category  |  d: Delete  |  h: Help  |  q: Quit"))
 	}
@@ -158,6 +439,25 @@ func renderMain(m model.Model) string {
 	return sb.String()
 }
 
+// categoryHeader renders the header line of a category in the grouped list,
+// with its icon and the number of visible commands in it
+func categoryHeader(m model.Model, category string) string {
+	count := 0
+	for _, cmd := range m.VisibleCommands {
+		if cmd.Category == category {
+			count++
+		}
+	}
+	label := category
+	if label == "" {
+		label = "Uncategorized"
+	}
+	if icon := m.Settings.CategoryIcons[category]; icon != "" {
+		label = icon + " " + label
+	}
+	return categoryStyle.UnsetPadding().Render(fmt.Sprintf("%s (%d)", label, count))
+}
+
 // renderExecution renders the command execution view
 func renderExecution(m model.Model) string {
 	var sb strings.Builder
@@ -167,12 +467,50 @@ func renderExecution(m model.Model) string {
 		return sb.String()
 	}
 
-	// Render title
-	sb.WriteString(titleStyle.Render(fmt.Sprintf("Executing: %s", m.ExecutingCommand.Name)))
-	sb.WriteString("\n\n")
+	// Render title; a log opened from the log or task list is tailed, not executed
+	if m.ShowLogs || m.ShowTasks {
+		sb.WriteString(titleStyle.Render(fmt.Sprintf("Log: %s", m.ExecutingCommand.Name)))
+		sb.WriteString("\n\n")
+		sb.WriteString(subtitleStyle.Render(fmt.Sprintf("File: %s (live)", m.ExecutingCommand.Command)))
+	} else if m.ViewingDryRun {
+		sb.WriteString(titleStyle.Render(fmt.Sprintf("Dry run: %s", m.ExecutingCommand.Name)))
+		sb.WriteString("\n\n")
+		sb.WriteString(subtitleStyle.Render("Nothing was run"))
+	} else if m.ViewingLastResult {
+		sb.WriteString(titleStyle.Render(fmt.Sprintf("Last result: %s", m.ExecutingCommand.Name)))
+		sb.WriteString("\n\n")
+		sb.WriteString(subtitleStyle.Render(fmt.Sprintf("Command: %s", firstLine(m.ExecutingCommand.Command))))
+	} else {
+		sb.WriteString(titleStyle.Render(fmt.Sprintf("Executing: %s", m.ExecutingCommand.Name)))
+		sb.WriteString("\n\n")
 
-	// Render command info with a simple spinner
-	sb.WriteString(subtitleStyle.Render(fmt.Sprintf("Command: %s", m.ExecutingCommand.Command)))
+		// Render command info with a simple spinner
+		sb.WriteString(subtitleStyle.Render(fmt.Sprintf("Command: %s", firstLine(m.ExecutingCommand.Command))))
+	}
+	if !m.ExecutionStart.IsZero() {
+		label := fmt.Sprintf("⏱ %s", m.ExecutionElapsed)
+		if !m.Spinning {
+			label = fmt.Sprintf("Took %s", m.ExecutionElapsed.Round(time.Millisecond))
+		}
+		sb.WriteString(" ")
+		sb.WriteString(categoryStyle.Render(label))
+	}
+	if m.BatchTotal > 0 {
+		status := "Running"
+		if !m.Spinning {
+			status = "Finished"
+		}
+		sb.WriteString(" ")
+		sb.WriteString(categoryStyle.Render(fmt.Sprintf("%s %d/%d", status, m.BatchIndex, m.BatchTotal)))
+	}
+	if m.Watching {
+		lastRun := "running..."
+		if !m.WatchLastRun.IsZero() {
+			lastRun = m.WatchLastRun.Format("15:04:05")
+		}
+		sb.WriteString(" ")
+		sb.WriteString(categoryStyle.Render(fmt.Sprintf("Every %s  |  Last run: %s", m.WatchInterval, lastRun)))
+	}
 	sb.WriteString("\n\n")
 
 	// Handle scrollable output
@@ -204,38 +542,6 @@ func renderExecution(m model.Model) string {
 		endLine = totalLines
 	}
 
-	// For very large outputs, show a warning and trimmed content
-	const maxProcessableLines = 5000
-	showingSummary := false
-
-	if totalLines > maxProcessableLines {
-		// For extremely large outputs, we'll show a warning and a subset of lines
-		if startLine < 100 {
-			// Near the top: show first 100 lines and 100 lines after scroll position
-			if endLine > startLine+100 {
-				endLine = startLine + 100
-			}
-		} else if startLine > totalLines-200 {
-			// Near the bottom: show last 200 lines
-			if startLine < totalLines-200 {
-				startLine = totalLines - 200
-			}
-		} else {
-			// In the middle: show 100 lines before and after scroll position
-			midPoint := startLine + (endLine-startLine)/2
-			startLine = midPoint - 50
-			if startLine < 0 {
-				startLine = 0
-			}
-			endLine = midPoint + 50
-			if endLine > totalLines {
-				endLine = totalLines
-			}
-		}
-
-		showingSummary = true
-	}
-
 	// Show scroll position indicator
 	if totalLines > visibleLines {
 		scrollPercent := 0.0
@@ -255,36 +561,265 @@ func renderExecution(m model.Model) string {
 
 		scrollBar := strings.Repeat("█", progressChars) + strings.Repeat("░", scrollBarWidth-progressChars)
 		scrollInfo := fmt.Sprintf(" %d/%d lines (%.0f%%)", startLine+1, totalLines, scrollPercent)
-
-		scrollStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4")).Bold(true)
-
-		sb.WriteString(scrollStyle.Render(scrollBar + scrollInfo))
-
-		if showingSummary {
-			sb.WriteString("\n")
-			sb.WriteString(errorStyle.Render(fmt.Sprintf("⚠️ Output is very large (%d lines). Showing partial content.", totalLines)))
+		if m.FollowOutput {
+			scrollInfo += "  [following]"
 		}
 
+		sb.WriteString(scrollStyle.Render(scrollBar + scrollInfo))
 		sb.WriteString("\n\n")
 	}
 
-	// Render visible output lines
-	visibleOutput := strings.Join(outputLines[startLine:endLine], "\n")
+	// Render visible output lines, highlighting the selection and coloring recognized log levels
+	selStart, selEnd := m.SelectionAnchor, m.SelectionCursor
+	if selStart > selEnd {
+		selStart, selEnd = selEnd, selStart
+	}
+	statusStyles := resultLineStyles(outputLines)
+	visible := make([]string, 0, endLine-startLine)
+	for i, line := range outputLines[startLine:endLine] {
+		lineNo := startLine + i
+		if m.Screen != nil {
+			// The screen of an interactive command, drawn as it is
+			visible = append(visible, line)
+		} else if m.SelectionActive && lineNo >= selStart && lineNo <= selEnd {
+			visible = append(visible, selectionStyle.Render(line))
+		} else if style, ok := statusStyles[lineNo]; ok {
+			visible = append(visible, style.Render(line))
+		} else {
+			visible = append(visible, colorizeLogLine(line, m.Settings.LogRules))
+		}
+	}
+	visibleOutput := strings.Join(visible, "\n")
 	sb.WriteString(outputStyle.Render(visibleOutput))
 
 	// Render help shortcuts
 	sb.WriteString("\n\n")
 
+	// Render the error or status message (e.g. copy confirmations)
+	if message := statusMessage(m); message != "" {
+		sb.WriteString(message)
+		sb.WriteString("\n")
+	}
+
 	// Add scroll instructions if content is scrollable
-	if totalLines > visibleLines {
-		sb.WriteString(helpStyle.Render("↑/↓: Scroll  |  PgUp/PgDn: Page Scroll  |  Home/End: Top/Bottom  |  Enter/Esc: Back"))
+	if m.CurrentMode == model.ModeConfirmQuit {
+		sb.WriteString(confirmStyle.Render("A command is still running. Quit anyway?"))
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render("y: Kill and Quit  |  n/Esc: Keep Running"))
+	} else if m.PTY != nil {
+		sb.WriteString(helpStyle.Render("Keys go to the command  |  Ctrl+]: Kill"))
+	} else if m.ExecutionCancel != nil {
+		sb.WriteString(helpStyle.Render("x/Ctrl+c: Cancel  |  ↑/↓: Scroll  |  End/F: Follow  |  Enter/Esc: Back"))
+	} else if m.Watching {
+		sb.WriteString(helpStyle.Render("w: Stop Watching  |  ↑/↓: Scroll  |  y: Copy  |  Enter/Esc: Back"))
+	} else if m.SelectionActive {
+		sb.WriteString(helpStyle.Render(fmt.Sprintf("Selecting lines %d-%d  |  ↑/↓: Extend  |  Y: Copy  |  v/Esc: Cancel", selStart+1, selEnd+1)))
 	} else {
-		sb.WriteString(helpStyle.Render("Enter/Esc: Back to list"))
+		// e only does something when the run wrote to stderr
+		streams := ""
+		if m.AlternateOutput != "" {
+			streams = "e: Separate Stderr  |  "
+			if m.SeparateStderr {
+				streams = "e: Combine Stderr  |  "
+			}
+		}
+		if totalLines > visibleLines {
+			sb.WriteString(helpStyle.Render("↑/↓: Scroll  |  PgUp/PgDn: Page Scroll  |  Home/End: Top/Bottom  |  F: Follow  |  y: Copy  |  s: Save  |  v: Select Lines  |  " + streams + "Enter/Esc: Back"))
+		} else {
+			sb.WriteString(helpStyle.Render("y: Copy  |  s: Save  |  v: Select Lines  |  " + streams + "Enter/Esc: Back to list"))
+		}
 	}
 
 	return sb.String()
 }
 
+// renderPalette renders the command palette overlay
+func renderPalette(m model.Model) string {
+	var sb strings.Builder
+
+	sb.WriteString(titleStyle.Render("Run Command"))
+	sb.WriteString("\n\n")
+
+	sb.WriteString("> ")
+	sb.WriteString(selectedItemStyle.Render(m.PaletteQuery))
+	sb.WriteString(cursorStyle.Render("_"))
+	sb.WriteString("\n\n")
+
+	// Show a window of results around the selection
+	const maxResults = 10
+	start := 0
+	if m.PaletteIndex >= maxResults {
+		start = m.PaletteIndex - maxResults + 1
+	}
+	end := start + maxResults
+	if end > len(m.PaletteResults) {
+		end = len(m.PaletteResults)
+	}
+
+	if len(m.PaletteResults) == 0 {
+		sb.WriteString(itemStyle.Render("No matching commands."))
+		sb.WriteString("\n")
+	}
+	for i := start; i < end; i++ {
+		cmd := m.PaletteResults[i]
+		label := fmt.Sprintf("%s (%s)", cmd.Name, cmd.Category)
+		if i == m.PaletteIndex {
+			sb.WriteString(selectedItemStyle.Render(label))
+			sb.WriteString(commandStyle.Render(firstLine(cmd.Command)))
+		} else {
+			sb.WriteString(itemStyle.Render(label))
+		}
+		sb.WriteString("\n")
+	}
+
+	if message := statusMessage(m); message != "" {
+		sb.WriteString("\n")
+		sb.WriteString(message)
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(helpStyle.Render(fmt.Sprintf("%d match(es)  |  ↑/↓: Select  |  Enter: Run  |  Esc: Close", len(m.PaletteResults))))
+
+	return sb.String()
+}
+
+// renderLogs renders the list of background run logs
+func renderLogs(m model.Model) string {
+	var sb strings.Builder
+
+	sb.WriteString(titleStyle.Render("Background Logs"))
+	sb.WriteString("\n\n")
+
+	if len(m.LogFiles) == 0 {
+		sb.WriteString(itemStyle.Render("No logs yet. Toggle background mode with b and run a command."))
+	}
+	for i, file := range m.LogFiles {
+		label := fmt.Sprintf("%s  %s  %s", file.ModTime.Format("2006-01-02 15:04:05"), formatSize(file.Size), file.Name)
+		if i == m.LogIndex {
+			sb.WriteString(selectedItemStyle.Render(label))
+		} else {
+			sb.WriteString(itemStyle.Render(label))
+		}
+		sb.WriteString("\n")
+	}
+
+	if message := statusMessage(m); message != "" {
+		sb.WriteString("\n")
+		sb.WriteString(message)
+	}
+
+	sb.WriteString("\n\n")
+	sb.WriteString(helpStyle.Render("↑/↓: Navigate  |  Enter: View (live)  |  r: Refresh  |  l/Esc: Back"))
+	return sb.String()
+}
+
+// renderTasks renders the background tasks started in this session
+func renderTasks(m model.Model) string {
+	var sb strings.Builder
+
+	sb.WriteString(titleStyle.Render("Background Tasks"))
+	sb.WriteString("\n\n")
+
+	if len(m.Tasks) == 0 {
+		sb.WriteString(itemStyle.Render("No background tasks. Toggle background mode with b and run a command."))
+	}
+	for i, task := range m.Tasks {
+		var status string
+		switch {
+		case !task.Done:
+			status = "⏳ running"
+		case task.ExitCode == 0:
+			status = fmt.Sprintf("✓ exit 0   %s", task.Finished.Sub(task.Started).Round(time.Second))
+		default:
+			status = fmt.Sprintf("✗ exit %-3d %s", task.ExitCode, task.Finished.Sub(task.Started).Round(time.Second))
+		}
+		label := fmt.Sprintf("%s  %s  %s  %s", task.Started.Format("15:04:05"), status, task.Name, task.LogPath)
+		if i == m.TaskIndex {
+			sb.WriteString(selectedItemStyle.Render(label))
+		} else {
+			sb.WriteString(itemStyle.Render(label))
+		}
+		sb.WriteString("\n")
+	}
+
+	if message := statusMessage(m); message != "" {
+		sb.WriteString("\n")
+		sb.WriteString(message)
+	}
+
+	sb.WriteString("\n\n")
+	sb.WriteString(helpStyle.Render("↑/↓: Navigate  |  Enter: View log (live)  |  c: Clear finished  |  T/Esc: Back"))
+	return sb.String()
+}
+
+// renderCategories renders the category manager with the number of commands
+// in each category
+func renderCategories(m model.Model) string {
+	var sb strings.Builder
+
+	sb.WriteString(titleStyle.Render("Categories"))
+	sb.WriteString("\n\n")
+
+	counts := map[string]int{}
+	for _, cmd := range m.AllCommands {
+		counts[cmd.Category]++
+	}
+	index := 0
+	for _, category := range m.Categories {
+		if model.PseudoCategory(category) {
+			continue
+		}
+		label := fmt.Sprintf("%s (%d)", category, counts[category])
+		if icon := m.Settings.CategoryIcons[category]; icon != "" {
+			label = icon + " " + label
+		}
+		if index == m.CategoryIndex {
+			sb.WriteString(selectedItemStyle.Render(label))
+		} else {
+			sb.WriteString(itemStyle.Render(label))
+		}
+		sb.WriteString("\n")
+		index++
+	}
+	if index == 0 {
+		sb.WriteString(itemStyle.Render("No categories yet. Set a command's Category in the form."))
+		sb.WriteString("\n")
+	}
+	if uncategorized := counts[""]; uncategorized > 0 {
+		sb.WriteString(mutedStyle.Render(fmt.Sprintf("  %d command(s) without a category", uncategorized)))
+		sb.WriteString("\n")
+	}
+
+	if message := statusMessage(m); message != "" {
+		sb.WriteString("\n")
+		sb.WriteString(message)
+	}
+
+	sb.WriteString("\n\n")
+	if m.RenamingCategory {
+		sb.WriteString("Rename to: ")
+		sb.WriteString(selectedItemStyle.Render(m.InputBuffer))
+		sb.WriteString(cursorStyle.Render("_"))
+		sb.WriteString("\n")
+		sb.WriteString(helpStyle.Render("An existing name merges the categories  |  Enter: Rename  |  Ctrl+u: Clear  |  Esc: Cancel"))
+	} else {
+		sb.WriteString(helpStyle.Render("↑/↓: Navigate  |  r/Enter: Rename  |  d d: Delete  |  C/Esc: Back"))
+	}
+	return sb.String()
+}
+
+// formatSize renders a byte count as B, KB or MB
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%6.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%6.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%6d B ", size)
+	}
+}
+
 // renderHelp renders the help view
 func renderHelp() string {
 	var sb strings.Builder
@@ -299,15 +834,40 @@ func renderHelp() string {
 		description string
 	}{
 		{"↑/↓", "Navigate up and down the command list"},
+		{"g/G", "Jump to the first/last command (also Home/End)"},
+		{"PgUp/PgDn", "Move a screenful up or down the list"},
 		{"Enter", "Execute the selected command"},
+		{"!", "Execute the selected command without confirmation"},
 		{"n", "Add a new command"},
 		{"e", "Edit the selected command"},
+		{"D", "Duplicate the selected command"},
 		{"d", "Delete the selected command"},
-		{"f", "Filter commands by name or tags"},
+		{"u", "Undo the last delete"},
+		{"L", "Protect/unprotect the selected command against edit and delete"},
+		{"*", "Favorite/unfavorite the selected command"},
+		{"f", "Filter commands by name, command, tags, description, category or path"},
 		{"c", "Filter by category"},
+		{"C", "Manage categories: rename, merge or delete"},
+		{"V", "Dry run: show the resolved command, shell, directory and environment"},
+		{"O", "Edit the commands file in $EDITOR and reload it"},
+		{"t", "Filter by tags"},
+		{"s", "Cycle sort order: config, name, recently run, most run"},
+		{"S", "Group the All list under category headers"},
+		{"i", "Show the description of every command (detailed list)"},
 		{"h", "Show/hide this help screen"},
 		{"b", "Toggle background execution mode"},
-		{"q/Esc", "Quit the application"},
+		{"l", "Browse background run logs and tail them live"},
+		{"Space", "Mark/unmark the selected command for a batch run"},
+		{"R", "Run the marked commands one after another (all at once in background mode)"},
+		{"T", "Show background tasks started in this session"},
+		{"o", "Reopen the last result of the selected command (needs save_results)"},
+		{"Ctrl+s", "Retry saving commands after a failed write"},
+		{"Esc", "Dismiss a warning, e.g. about a recovered commands file"},
+		{"Ctrl+p", "Open the command palette (also from the output view)"},
+		{"w", "Watch: re-run the selected command on an interval"},
+		{"p", "Pin the selected command to a quick-launch slot (1-9)"},
+		{"Alt+1..9", "Execute the command pinned to that slot"},
+		{"q/Esc", "Quit the application (asks first while a command runs)"},
 	}
 
 	for _, s := range shortcuts {
@@ -337,13 +897,8 @@ func renderForm(m model.Model) string {
 	formLabelStyle := categoryStyle
 	formValueStyle := commandStyle
 	activeFormValueStyle := selectedItemStyle
-	editingFormStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#008800")).
-		Bold(true)
-	formCursorStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#FF00FF"))
+	editingFormStyle := editingStyle
+	formCursorStyle := cursorStyle
 
 	// Define form fields with their labels and help text
 	type formFieldInfo struct {
@@ -355,13 +910,28 @@ func renderForm(m model.Model) string {
 	formFields := []formFieldInfo{
 		{"Name", model.FieldName, "Display name for the command"},
 		{"Command", model.FieldCommand, "The actual shell command to execute"},
+		{"PreCommand", model.FieldPreCommand, "Optional setup run first; the command is skipped if it fails"},
+		{"PostCommand", model.FieldPostCommand, "Optional cleanup run after the command, even when it failed"},
 		{"Category", model.FieldCategory, "Category for organization (e.g., System, Network)"},
 		{"Description", model.FieldDescription, "Brief description of what the command does"},
 		{"Tags", model.FieldTags, "Comma-separated tags for filtering"},
-		{"WorkingDirMode", model.FieldWorkingDirMode, "current|home|absolute"},
-		{"WorkingDirPath", model.FieldWorkingDirPath, "Used when mode is absolute; supports ~, $HOME, ${cwd}"},
+		{"Aliases", model.FieldAliases, "Comma-separated short names, e.g. for go-recipe run dk"},
+		{"WorkingDirMode", model.FieldWorkingDirMode, "current|home|absolute|relative"},
+		{"WorkingDirPath", model.FieldWorkingDirPath, "Used when mode is absolute or relative; supports ~, $HOME, ${cwd}"},
+		{"CreateWorkingDir", model.FieldCreateWorkingDir, "true/false – create the working directory path if it is missing"},
 		{"UseShell", model.FieldUseShell, "true/false – run via shell to support pipes and quotes"},
+		{"Shell", model.FieldShell, "Shell used when UseShell is true, e.g. zsh or sh (empty: $SHELL, or bash)"},
 		{"Interactive", model.FieldInteractive, "true/false – run attached (e.g., htop, ssh)"},
+		{"Confirm", model.FieldConfirm, "true/false – always ask before running (for destructive commands)"},
+		{"EnvFile", model.FieldEnvFile, "Optional .env file with KEY=VALUE lines; supports ~, $HOME, ${cwd}"},
+		{"Env", model.FieldEnv, "Comma-separated KEY=VALUE pairs, e.g. AWS_PROFILE=dev, NODE_ENV=test"},
+		{"StdinFile", model.FieldStdinFile, "Optional file piped to the command's stdin; supports ~, $HOME, ${cwd}"},
+		{"OpenOutputInEditor", model.FieldOpenOutputInEditor, "true/false – open the output in $PAGER/$EDITOR after the run"},
+		{"Redact", model.FieldRedact, "Regexps separated by ';' – matches are shown as ****"},
+		{"ColorMode", model.FieldColorMode, "auto|always|never – always runs in a terminal so tools emit colors, never strips them"},
+		{"TimeoutSeconds", model.FieldTimeoutSeconds, "Kill the command after this many seconds (empty or 0: no timeout)"},
+		{"Retries", model.FieldRetries, "Run a failing command again up to this many times (empty or 0: no retries)"},
+		{"RetryDelaySeconds", model.FieldRetryDelaySeconds, "Seconds to wait between retries"},
 	}
 
 	for _, fieldInfo := range formFields {
@@ -371,55 +941,83 @@ func renderForm(m model.Model) string {
 		value := m.GetFormFieldValue(fieldInfo.field)
 
 		// Render field label (highlight if active)
+		labelStyle := formLabelStyle
 		if isActive {
 			if m.EditingFormField {
-				sb.WriteString(editingFormStyle.Render(fieldInfo.label + ": "))
+				labelStyle = editingFormStyle
 			} else {
-				sb.WriteString(selectedCategoryStyle.Render(fieldInfo.label + ": "))
+				labelStyle = selectedCategoryStyle
 			}
-		} else {
-			sb.WriteString(formLabelStyle.Render(fieldInfo.label + ": "))
 		}
+		label := labelStyle.Render(fieldInfo.label + ": ")
+		sb.WriteString(label)
 
 		// Render field value with appropriate styling
 		if m.EditingFormField && isActive {
 			// When editing, show the input buffer with cursor
-			sb.WriteString(editingFormStyle.Render(m.FormInputBuffer))
-			sb.WriteString(formCursorStyle.Render("_"))
+			sb.WriteString(renderInput(editingFormStyle, formCursorStyle, m.FormInputBuffer, m.FormCursor, lipgloss.Width(label)))
+			if m.FormPreview != "" {
+				sb.WriteString("\n")
+				if m.FormPreviewError {
+					sb.WriteString(errorStyle.Render("  → " + m.FormPreview))
+				} else {
+					sb.WriteString(descriptionStyle.Render("  → " + m.FormPreview))
+				}
+			}
+		} else if choices := fieldInfo.field.Choices(); choices != nil {
+			// Fields with fixed choices show the current one between arrows
+			if value == "" {
+				value = choices[0]
+			}
+			if isActive {
+				sb.WriteString(activeFormValueStyle.Render("◀ " + value + " ▶"))
+			} else {
+				sb.WriteString(formValueStyle.Render(value))
+			}
+		} else if fieldInfo.field == model.FieldWorkingDirPath && !m.FormCommand.UsesWorkingDirPath() {
+			// The path only matters in absolute and relative mode
+			text := value
+			if text == "" {
+				text = "<" + fieldInfo.help + ">"
+			}
+			sb.WriteString(mutedStyle.Render(text))
 		} else if value == "" {
 			// Show placeholder text for empty fields
 			if isActive {
 				sb.WriteString(activeFormValueStyle.Render("<" + fieldInfo.help + ">"))
 			} else {
-				sb.WriteString(lipgloss.NewStyle().
-					Foreground(lipgloss.Color("#666666")).
-					Render("<" + fieldInfo.help + ">"))
+				sb.WriteString(mutedStyle.Render("<" + fieldInfo.help + ">"))
 			}
 		} else {
-			// Show the value with appropriate styling
+			// Show the value with appropriate styling; a multi-line command
+			// continues under its first line
+			style := formValueStyle
 			if isActive {
-				sb.WriteString(activeFormValueStyle.Render(value))
-			} else {
-				sb.WriteString(formValueStyle.Render(value))
+				style = activeFormValueStyle
 			}
+			sb.WriteString(renderLines(style, value, lipgloss.Width(label)))
 		}
 
 		sb.WriteString("\n")
 	}
 
-	// Render error
-	if m.Error != "" {
+	// Render the error or status message
+	if message := statusMessage(m); message != "" {
 		sb.WriteString("\n")
-		sb.WriteString(errorStyle.Render(m.Error))
+		sb.WriteString(message)
 	}
 
 	// Render help shortcuts and field hints
 	sb.WriteString("\n\n")
 
 	if m.EditingFormField {
-		sb.WriteString(helpStyle.Render("Enter: Confirm  |  Tab: Next Field  |  Esc: Cancel Edit  |  Ctrl+u: Clear Input"))
+		help := "Enter: Confirm  |  Tab: Next Field  |  ←/→ Home/End: Move Cursor  |  Esc: Cancel Edit  |  Ctrl+u: Clear Input"
+		if m.ActiveFormField == model.FieldCommand {
+			help += "  |  Ctrl+j/Alt+Enter: New Line"
+		}
+		sb.WriteString(helpStyle.Render(help))
 	} else {
-		sb.WriteString(helpStyle.Render("↑/↓: Navigate Fields  |  Enter: Edit Field  |  Tab: Next Field  |  y: Save  |  Esc: Cancel"))
+		sb.WriteString(helpStyle.Render("↑/↓: Navigate Fields  |  Enter: Edit Field  |  ←/→: Change Choice  |  Tab: Next Field  |  y: Save  |  Esc: Cancel"))
 		sb.WriteString("\n")
 		sb.WriteString(descriptionStyle.Render("Fill in the fields above to add your new command."))
 		// Additional hints for UseShell and Interactive
@@ -431,3 +1029,94 @@ func renderForm(m model.Model) string {
 
 	return sb.String()
 }
+
+// batchConfirmation asks whether to run the marked commands, listing those
+// that ask before they run
+func batchConfirmation(m model.Model) string {
+	var sb strings.Builder
+	marked := 0
+	for _, cmd := range m.AllCommands {
+		if !m.Marked[cmd.ID] {
+			continue
+		}
+		marked++
+		if cmd.AsksBeforeRun(m.Settings) {
+			fmt.Fprintf(&sb, "\n%s: $ %s", cmd.Name, firstLine(cmd.Command))
+		}
+	}
+	return fmt.Sprintf("Run the %d marked commands? These ask before running:\n%s", marked, sb.String())
+}
+
+// firstLine returns the first line of a multi-line command followed by " …",
+// so it takes a single line in lists and headers
+func firstLine(s string) string {
+	if line, _, ok := strings.Cut(s, "\n"); ok {
+		return line + " …"
+	}
+	return s
+}
+
+// renderLines renders each line of s in style, indenting every line but the
+// first by indent columns so they line up under it
+func renderLines(style lipgloss.Style, s string, indent int) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = style.Render(line)
+	}
+	return strings.Join(lines, "\n"+strings.Repeat(" ", indent))
+}
+
+// renderInput renders the text being typed like renderLines, drawing the
+// cursor over the character at pos, or as "_" at the end of a line
+func renderInput(style, cursor lipgloss.Style, input string, pos, indent int) string {
+	runes := []rune(input)
+	pos = max(0, min(pos, len(runes)))
+	under, rest := "_", runes[pos:]
+	if len(rest) > 0 && rest[0] != '\n' {
+		under, rest = string(rest[0]), rest[1:]
+	}
+	before := strings.Split(string(runes[:pos]), "\n")
+	after := strings.Split(string(rest), "\n")
+
+	var lines []string
+	for _, line := range before[:len(before)-1] {
+		lines = append(lines, style.Render(line))
+	}
+	lines = append(lines, style.Render(before[len(before)-1])+cursor.Render(under)+style.Render(after[0]))
+	for _, line := range after[1:] {
+		lines = append(lines, style.Render(line))
+	}
+	return strings.Join(lines, "\n"+strings.Repeat(" ", indent))
+}
+
+// highlightMatches renders text in base with the characters matched by the
+// words of filter picked out in matchStyle. Matching ignores case and accents,
+// so the text keeps its own spelling.
+func highlightMatches(text, filter string, base lipgloss.Style) string {
+	matched := map[int]bool{}
+	for _, word := range strings.Fields(filter) {
+		_, positions := model.FuzzyMatch(text, word)
+		for _, p := range positions {
+			matched[p] = true
+		}
+	}
+	if len(matched) == 0 {
+		return base.Render(text)
+	}
+
+	highlight := matchStyle.Inherit(base)
+	runes := []rune(text)
+	var sb strings.Builder
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || matched[i] != matched[start] {
+			style := base
+			if matched[start] {
+				style = highlight
+			}
+			sb.WriteString(style.Render(string(runes[start:i])))
+			start = i
+		}
+	}
+	return sb.String()
+}
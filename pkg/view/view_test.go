@@ -0,0 +1,77 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Tomlord1122/go-recipe/pkg/model"
+)
+
+func TestMainViewFitsTheHeight(t *testing.T) {
+	var commands []model.Command
+	for i := range 100 {
+		commands = append(commands, model.Command{ID: fmt.Sprint(i), Name: fmt.Sprint("cmd", i), Command: "ls", Category: "Files"})
+	}
+	base := model.NewModel(false)
+	base.Width, base.Height = 100, 40
+	base.AllCommands, base.VisibleCommands = commands, commands
+	base.Categories = []string{"All", "Files"}
+	base.ActiveCategory = "All"
+	// In the middle of the list, so it is cut off above and below
+	base.SelectedIndex, base.ListOffset = 50, 45
+
+	tests := []struct {
+		name  string
+		setup func(m *model.Model)
+	}{
+		{"plain", func(m *model.Model) {}},
+		{"sorted", func(m *model.Model) { m.SortMode = model.SortRecent }},
+		{"tags", func(m *model.Model) { m.ActiveTags = []string{"disk"} }},
+		{"filter", func(m *model.Model) { m.FilterText = "cmd" }},
+		{"typing a filter", func(m *model.Model) { m.CurrentMode = model.ModeFilterInput }},
+		{"error", func(m *model.Model) { m.Error = "failed" }},
+		{"unsaved", func(m *model.Model) { m.UnsavedChanges = true }},
+		{"warning", func(m *model.Model) { m.Warning = "commands file recovered" }},
+		{"every status", func(m *model.Model) {
+			m.Error, m.Info, m.Warning, m.UnsavedChanges = "failed", "saved", "commands file recovered", true
+		}},
+		{"confirm", func(m *model.Model) {
+			m.CurrentMode = model.ModeConfirm
+			m.ConfirmCommand = &model.Command{Name: "wipe", Command: "rm -rf build\nrm -rf dist"}
+		}},
+		{"confirm batch", func(m *model.Model) {
+			m.CurrentMode = model.ModeConfirm
+			m.ConfirmBatch = true
+			m.AllCommands = append([]model.Command{}, commands...)
+			m.AllCommands[1].Confirm = true
+			m.AllCommands[2].Confirm = true
+			m.Marked = map[string]bool{"1": true, "2": true, "3": true}
+		}},
+		{"watch interval", func(m *model.Model) {
+			m.CurrentMode = model.ModeWatchInterval
+			m.WatchCommand = &commands[0]
+		}},
+		{"placeholder", func(m *model.Model) {
+			m.CurrentMode = model.ModePlaceholder
+			m.PlaceholderCommand = &commands[0]
+			m.PlaceholderNames = []string{"host"}
+			m.PlaceholderValues = map[string]string{}
+			m.Settings.PlaceholderHistory = map[string][]string{"host": {"a", "b", "c"}}
+		}},
+		{"tag picker", func(m *model.Model) {
+			m.CurrentMode = model.ModeTagPicker
+			m.TagOptions = []string{"disk", "net", "docker", "git"}
+		}},
+		{"tag picker without tags", func(m *model.Model) { m.CurrentMode = model.ModeTagPicker }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := base
+			tt.setup(&m)
+			if got := strings.Count(renderMain(m), "\n") + 1; got != m.Height {
+				t.Errorf("main view takes %d lines with %d list rows, want the height %d", got, m.ListRows(), m.Height)
+			}
+		})
+	}
+}